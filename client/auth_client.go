@@ -2,36 +2,133 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"grpc-go/pb"
+	"sync"
 	"time"
 )
 
 type AuthClient struct {
-	service  pb.AuthServiceClient
-	username string
-	password string
+	service      pb.AuthServiceClient
+	mutex        sync.Mutex
+	refreshToken string
 }
 
-func NewAuthClient(cc *grpc.ClientConn, username, password string) *AuthClient {
+func NewAuthClient(cc *grpc.ClientConn) *AuthClient {
 	service := pb.NewAuthServiceClient(cc)
 	return &AuthClient{
-		service:  service,
-		username: username,
-		password: password,
+		service: service,
 	}
 }
 
-func (client *AuthClient) Login() (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*500000)
+// Login returns an access token and keeps the refresh token, the password isn't needed anymore afterwards
+func (client *AuthClient) Login(username string, password string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	req := &pb.LoginRequest{
-		Username: client.username,
-		Password: client.password,
+		Username: username,
+		Password: password,
 	}
 	resp, err := client.service.Login(ctx, req)
 	if err != nil {
 		return "", err
 	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.refreshToken = resp.GetRefreshToken()
+	return resp.GetAccessToken(), nil
+}
+
+// Refresh exchanges the refresh token for a new access token and a new refresh token
+func (client *AuthClient) Refresh() (string, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if client.refreshToken == "" {
+		return "", fmt.Errorf("not logged in")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.service.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: client.refreshToken})
+	if err != nil {
+		return "", err
+	}
+	client.refreshToken = resp.GetRefreshToken()
 	return resp.GetAccessToken(), nil
 }
+
+// Logout revokes the access token and the refresh token, a new login is needed afterwards
+func (client *AuthClient) Logout(accessToken string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", accessToken)
+	_, err := client.service.Logout(ctx, &pb.LogoutRequest{RefreshToken: client.refreshToken})
+	if err != nil {
+		return err
+	}
+	client.refreshToken = ""
+	return nil
+}
+
+// GetSigningKeys returns the public keys the access tokens are verified with
+func (client *AuthClient) GetSigningKeys() ([]*pb.JSONWebKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.service.GetSigningKeys(ctx, &pb.GetSigningKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetKeys(), nil
+}
+
+// Register creates a user, it has to log in afterwards
+func (client *AuthClient) Register(username string, password string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &pb.RegisterRequest{
+		Username: username,
+		Password: password,
+	}
+	_, err := client.service.Register(ctx, req)
+	return err
+}
+
+// ChangePassword changes the password of the user of the access token
+func (client *AuthClient) ChangePassword(accessToken string, oldPassword string, newPassword string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", accessToken)
+	req := &pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+	_, err := client.service.ChangePassword(ctx, req)
+	return err
+}
+
+// RequestPasswordReset has a reset code sent to the user
+func (client *AuthClient) RequestPasswordReset(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.service.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: username})
+	return err
+}
+
+// ConfirmPasswordReset sets the password of the user with the code that was sent to them
+func (client *AuthClient) ConfirmPasswordReset(username string, code string, newPassword string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &pb.ConfirmPasswordResetRequest{
+		Username:    username,
+		Code:        code,
+		NewPassword: newPassword,
+	}
+	_, err := client.service.ConfirmPasswordReset(ctx, req)
+	return err
+}
@@ -3,17 +3,23 @@ package client
 import (
 	"context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"log"
+	"sync"
 	"time"
 )
 
 type AuthInterceptor struct {
 	authClient  *AuthClient
 	authMethods map[string]bool
+	mutex       sync.RWMutex
 	accessToken string
 }
 
+// NewAuthInterceptor attaches the access token to the authMethods and refreshes it every refreshDuration.
+// The authClient must be logged in.
 func NewAuthInterceptor(authClient *AuthClient, authMethods map[string]bool, refreshDuration time.Duration) (*AuthInterceptor, error) {
 	interceptor := &AuthInterceptor{
 		authClient:  authClient,
@@ -51,6 +57,8 @@ func (interceptor *AuthInterceptor) Stream() grpc.StreamClientInterceptor {
 }
 
 func (interceptor *AuthInterceptor) attachToken(ctx context.Context) context.Context {
+	interceptor.mutex.RLock()
+	defer interceptor.mutex.RUnlock()
 	return metadata.AppendToOutgoingContext(ctx, "authorization", interceptor.accessToken)
 }
 
@@ -65,6 +73,11 @@ func (interceptor *AuthInterceptor) scheduleRefreshToken(refreshDuration time.Du
 		for {
 			time.Sleep(wait)
 			err := interceptor.refreshToken()
+			if status.Code(err) == codes.Unauthenticated {
+				// the refresh token expired or was revoked, only a new login can get another one
+				log.Printf("stop refreshing the access token: %v", err)
+				return
+			}
 			if err != nil {
 				wait = time.Second
 			} else {
@@ -76,11 +89,13 @@ func (interceptor *AuthInterceptor) scheduleRefreshToken(refreshDuration time.Du
 }
 
 func (interceptor *AuthInterceptor) refreshToken() error {
-	token, err := interceptor.authClient.Login()
+	token, err := interceptor.authClient.Refresh()
 	if err != nil {
 		return err
 	}
+	interceptor.mutex.Lock()
 	interceptor.accessToken = token
+	interceptor.mutex.Unlock()
 	log.Printf("token refreshed: %v", token)
 	return nil
 }
@@ -3,18 +3,29 @@ package client
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"grpc-go/pb"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+const (
+	maxUploadAttempts = 5
+	uploadRetryDelay  = time.Second
+)
+
 type LaptopClient struct {
 	service pb.LaptopServiceClient
 }
@@ -49,6 +60,68 @@ func (client *LaptopClient) CreateLaptop(laptop *pb.Laptop) {
 	log.Printf("create laptop with id: %s\n", res.Id)
 }
 
+// GetLaptop returns the laptop with the given id, the error has codes.NotFound if it doesn't exist
+func (client *LaptopClient) GetLaptop(laptopId string) (*pb.Laptop, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.GetLaptop(ctx, &pb.GetLaptopRequest{Id: laptopId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get laptop: %w", err)
+	}
+	return res.GetLaptop(), nil
+}
+
+// BatchGetLaptops returns the laptops that exist and the ids of the laptops that don't
+func (client *LaptopClient) BatchGetLaptops(laptopIds []string) ([]*pb.Laptop, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.BatchGetLaptops(ctx, &pb.BatchGetLaptopsRequest{Ids: laptopIds})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot batch get laptops: %w", err)
+	}
+	return res.GetLaptops(), res.GetMissingIds(), nil
+}
+
+// UpdateLaptop copies the fields listed in paths from laptop to the stored laptop with the same id.
+// The update fails with codes.Aborted if expectedVersion is not 0 and the stored laptop has another version.
+func (client *LaptopClient) UpdateLaptop(laptop *pb.Laptop, paths []string, expectedVersion uint64) (*pb.Laptop, error) {
+	req := &pb.UpdateLaptopRequest{
+		Laptop:          laptop,
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: paths},
+		ExpectedVersion: expectedVersion,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.UpdateLaptop(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update laptop: %w", err)
+	}
+	log.Printf("updated laptop with id: %s to version %d", res.GetLaptop().GetId(), res.GetLaptop().GetVersion())
+	return res.GetLaptop(), nil
+}
+
+// DeleteLaptop removes the laptop, see UpdateLaptop for expectedVersion
+func (client *LaptopClient) DeleteLaptop(laptopId string, expectedVersion uint64) error {
+	req := &pb.DeleteLaptopRequest{
+		Id:              laptopId,
+		ExpectedVersion: expectedVersion,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.service.DeleteLaptop(ctx, req)
+	if err != nil {
+		return fmt.Errorf("cannot delete laptop: %w", err)
+	}
+	log.Printf("deleted laptop with id: %s", laptopId)
+	return nil
+}
+
 func (client *LaptopClient) SearchLaptop(filter *pb.Filter) {
 	log.Printf("search filter: %v", filter)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*500000000)
@@ -81,29 +154,111 @@ func (client *LaptopClient) SearchLaptop(filter *pb.Filter) {
 	}
 }
 
-func (client *LaptopClient) UploadImage(laptopId string, filename string) {
+// GetLaptopFacets returns value counts and numeric aggregates of the laptops matching the filter
+func (client *LaptopClient) GetLaptopFacets(filter *pb.Filter, spec *pb.FacetSpec) (*pb.Facets, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.GetLaptopFacets(ctx, &pb.GetLaptopFacetsRequest{Filter: filter, Spec: spec})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get laptop facets: %w", err)
+	}
+	return res.GetFacets(), nil
+}
+
+// WatchLaptops calls handle for every change of a laptop matching the filter until the context is done.
+// Pass the epoch and the revision of the last handled event to resume after a disconnect.
+func (client *LaptopClient) WatchLaptops(ctx context.Context, filter *pb.Filter, startEpoch string, startRevision uint64, handle func(event *pb.LaptopEvent) error) error {
+	req := &pb.WatchLaptopsRequest{
+		Filter:        filter,
+		StartRevision: startRevision,
+		StartEpoch:    startEpoch,
+	}
+	stream, err := client.service.WatchLaptops(ctx, req)
+	if err != nil {
+		return fmt.Errorf("cannot watch laptops: %w", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot receive laptop event: %w", err)
+		}
+		event := res.GetEvent()
+		log.Printf("laptop %s %v at revision %d", event.GetLaptop().GetId(), event.GetType(), event.GetRevision())
+		err = handle(event)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// UploadImage uploads the image file of the laptop. The upload is resumable, after a failure
+// it continues from the offset the server has committed instead of sending the whole file again.
+// The digest returned by the server is checked against the content of the file.
+func (client *LaptopClient) UploadImage(laptopId string, filename string) (string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Fatal("cannot open image file: ", err)
+		return "", fmt.Errorf("cannot open image file: %w", err)
 	}
 	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("cannot read image file: %w", err)
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	info := &pb.ImageInfo{
+		LaptopId:  laptopId,
+		ImageType: strings.TrimPrefix(filepath.Ext(filename), "."),
+		UploadId:  uuid.New().String(),
+	}
+	for attempt := 1; ; attempt++ {
+		res, err := client.uploadImage(file, info)
+		if err == nil {
+			if res.GetDigest() != digest {
+				return "", fmt.Errorf("image %s has digest %s but the file has %s", res.GetId(), res.GetDigest(), digest)
+			}
+			return res.GetId(), nil
+		}
+		if attempt == maxUploadAttempts || !retryableUploadError(err) {
+			return "", err
+		}
+		log.Printf("upload %s failed, retrying: %v", info.UploadId, err)
+		time.Sleep(time.Duration(attempt) * uploadRetryDelay)
+	}
+}
+
+// uploadImage sends the part of the file the server hasn't committed yet
+func (client *LaptopClient) uploadImage(file *os.File, info *pb.ImageInfo) (*pb.UploadImageResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*600)
 	defer cancel()
-	req := &pb.UploadImageRequest{
-		Data: &pb.UploadImageRequest_Info{
-			Info: &pb.ImageInfo{
-				LaptopId:  laptopId,
-				ImageType: filepath.Ext(filename),
-			},
-		},
+
+	upload, err := client.service.GetUploadStatus(ctx, &pb.GetUploadStatusRequest{UploadId: info.UploadId})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("cannot get upload status: %w", err)
 	}
+	info.Offset = upload.GetOffset()
+	_, err = file.Seek(int64(info.Offset), io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek image file: %w", err)
+	}
+
 	stream, err := client.service.UploadImage(ctx)
 	if err != nil {
-		log.Fatal("cannot upload image: ", err)
+		return nil, fmt.Errorf("cannot upload image: %w", err)
 	}
-	err = stream.Send(req)
+	err = stream.Send(&pb.UploadImageRequest{
+		Data: &pb.UploadImageRequest_Info{
+			Info: info,
+		},
+	})
 	if err != nil {
-		log.Fatal("cannot send image info to server: ", err, stream.RecvMsg(nil))
+		return nil, fmt.Errorf("cannot send image info to server: %w", sendError(stream, err))
 	}
 	reader := bufio.NewReader(file)
 	buffer := make([]byte, 102400)
@@ -113,7 +268,7 @@ func (client *LaptopClient) UploadImage(laptopId string, filename string) {
 			break
 		}
 		if err != nil {
-			log.Fatal("cannot read chunk to buffer: ", err)
+			return nil, fmt.Errorf("cannot read chunk to buffer: %w", err)
 		}
 		req := &pb.UploadImageRequest{
 			Data: &pb.UploadImageRequest_ChunkData{
@@ -122,14 +277,118 @@ func (client *LaptopClient) UploadImage(laptopId string, filename string) {
 		}
 		err = stream.Send(req)
 		if err != nil {
-			log.Fatal("cannot send chunk to server: ", err)
+			return nil, fmt.Errorf("cannot send chunk to server: %w", sendError(stream, err))
 		}
 	}
 	res, err := stream.CloseAndRecv()
 	if err != nil {
-		log.Fatal("cannot receive response: ", err)
+		return nil, fmt.Errorf("cannot receive response: %w", err)
 	}
 	log.Printf("image upload with id: %s, size: %d", res.GetId(), res.GetSize())
+	return res, nil
+}
+
+// sendError returns the status the server ended the stream with if a send failed because of it,
+// otherwise the error of the send
+func sendError(stream grpc.ClientStream, err error) error {
+	if err == io.EOF {
+		recvErr := stream.RecvMsg(nil)
+		if recvErr != nil {
+			return recvErr
+		}
+	}
+	return err
+}
+
+// retryableUploadError reports whether an upload may succeed when it is resumed
+func retryableUploadError(err error) bool {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return false
+	}
+	switch grpcErr.GRPCStatus().Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Canceled, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// ListImages returns the images uploaded for the laptop
+func (client *LaptopClient) ListImages(laptopId string) ([]*pb.ImageInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.ListImages(ctx, &pb.ListImagesRequest{LaptopId: laptopId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list images: %w", err)
+	}
+	return res.GetImages(), nil
+}
+
+// DownloadImage writes the image to filename. A maxSize other than 0 downloads the largest rendition
+// of the image that fits in maxSize x maxSize pixels. The file only appears once the whole image has been received.
+func (client *LaptopClient) DownloadImage(imageId string, maxSize uint32, filename string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*600)
+	defer cancel()
+
+	req := &pb.DownloadImageRequest{
+		ImageId: imageId,
+		MaxSize: maxSize,
+	}
+	stream, err := client.service.DownloadImage(ctx, req)
+	if err != nil {
+		return fmt.Errorf("cannot download image: %w", err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("cannot receive image info: %w", err)
+	}
+	info := res.GetInfo()
+	if info == nil {
+		return fmt.Errorf("expected image info as the first message")
+	}
+	expectedSize := info.GetSize()
+	if res.GetRendition() != nil {
+		expectedSize = res.GetRendition().GetSize()
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create image file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var size uint64
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot receive chunk data: %w", err)
+		}
+		chunk := res.GetChunkData()
+		size += uint64(len(chunk))
+		_, err = file.Write(chunk)
+		if err != nil {
+			return fmt.Errorf("cannot write chunk data: %w", err)
+		}
+	}
+	if size != expectedSize {
+		return fmt.Errorf("received %d bytes but the image has %d", size, expectedSize)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close image file: %w", err)
+	}
+	err = os.Rename(file.Name(), filename)
+	if err != nil {
+		return fmt.Errorf("cannot rename image file: %w", err)
+	}
+	log.Printf("image %s downloaded to %s, size: %d", imageId, filename, size)
+	return nil
 }
 
 func (client *LaptopClient) RateLaptop(laptopIds []string, scores []float64) error {
@@ -155,6 +414,10 @@ func (client *LaptopClient) RateLaptop(laptopIds []string, scores []float64) err
 				waitResponse <- fmt.Errorf("cannot receive stream response: %v", err)
 				return
 			}
+			if res.GetError() != "" {
+				log.Printf("cannot rate laptop %s: %s", res.GetLaptopId(), res.GetError())
+				continue
+			}
 
 			log.Println("receive response: ", res)
 		}
@@ -181,3 +444,42 @@ func (client *LaptopClient) RateLaptop(laptopIds []string, scores []float64) err
 	err = <-waitResponse
 	return err
 }
+
+// RetractRating removes the score the logged in user gave to the laptop
+func (client *LaptopClient) RetractRating(laptopId string) (*pb.RetractRatingResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.RetractRating(ctx, &pb.RetractRatingRequest{LaptopId: laptopId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot retract rating: %w", err)
+	}
+	return res, nil
+}
+
+func (client *LaptopClient) GetLaptopRating(laptopId string) (*pb.LaptopRating, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.GetLaptopRating(ctx, &pb.GetLaptopRatingRequest{LaptopId: laptopId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get laptop rating: %w", err)
+	}
+	return res.GetRating(), nil
+}
+
+// ListTopRatedLaptops returns up to limit laptops matching the filter with the highest weighted score
+func (client *LaptopClient) ListTopRatedLaptops(filter *pb.Filter, limit uint32) ([]*pb.RatedLaptop, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.ListTopRatedLaptopsRequest{
+		Filter: filter,
+		Limit:  limit,
+	}
+	res, err := client.service.ListTopRatedLaptops(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list top rated laptops: %w", err)
+	}
+	return res.GetLaptops(), nil
+}
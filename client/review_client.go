@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"grpc-go/pb"
+	"time"
+)
+
+type ReviewClient struct {
+	service pb.ReviewServiceClient
+}
+
+func NewReviewClient(conn *grpc.ClientConn) *ReviewClient {
+	service := pb.NewReviewServiceClient(conn)
+	return &ReviewClient{
+		service: service,
+	}
+}
+
+// CreateReview publishes a review of the laptop as the logged in user, score replaces their rating of the laptop
+func (client *ReviewClient) CreateReview(laptopId string, score float64, title string, body string) (*pb.Review, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.CreateReviewRequest{
+		LaptopId: laptopId,
+		Score:    score,
+		Title:    title,
+		Body:     body,
+	}
+	res, err := client.service.CreateReview(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create review: %w", err)
+	}
+	return res.GetReview(), nil
+}
+
+// ListReviews returns a page of the published reviews of the laptop and the token of the next page
+func (client *ReviewClient) ListReviews(laptopId string, orderBy pb.ListReviewsRequest_OrderBy, pageToken string) ([]*pb.Review, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.ListReviewsRequest{
+		LaptopId:  laptopId,
+		OrderBy:   orderBy,
+		PageToken: pageToken,
+	}
+	res, err := client.service.ListReviews(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot list reviews: %w", err)
+	}
+	return res.GetReviews(), res.GetNextPageToken(), nil
+}
+
+func (client *ReviewClient) VoteReviewHelpful(reviewId string, helpful bool) (uint32, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.VoteReviewHelpful(ctx, &pb.VoteReviewHelpfulRequest{ReviewId: reviewId, Helpful: helpful})
+	if err != nil {
+		return 0, fmt.Errorf("cannot vote for review: %w", err)
+	}
+	return res.GetHelpfulCount(), nil
+}
+
+func (client *ReviewClient) SetReviewStatus(reviewId string, status pb.Review_Status) (*pb.Review, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.SetReviewStatus(ctx, &pb.SetReviewStatusRequest{ReviewId: reviewId, Status: status})
+	if err != nil {
+		return nil, fmt.Errorf("cannot set review status: %w", err)
+	}
+	return res.GetReview(), nil
+}
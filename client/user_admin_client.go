@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"grpc-go/pb"
+	"time"
+)
+
+type UserAdminClient struct {
+	service pb.UserAdminServiceClient
+}
+
+func NewUserAdminClient(conn *grpc.ClientConn) *UserAdminClient {
+	service := pb.NewUserAdminServiceClient(conn)
+	return &UserAdminClient{
+		service: service,
+	}
+}
+
+// ListUsers returns a page of the users ordered by username and the token of the next page
+func (client *UserAdminClient) ListUsers(pageSize uint32, pageToken string) ([]*pb.User, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.ListUsersRequest{
+		PageSize:  pageSize,
+		PageToken: pageToken,
+	}
+	res, err := client.service.ListUsers(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot list users: %w", err)
+	}
+	return res.GetUsers(), res.GetNextPageToken(), nil
+}
+
+func (client *UserAdminClient) GetUser(username string) (*pb.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.GetUser(ctx, &pb.GetUserRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get user: %w", err)
+	}
+	return res.GetUser(), nil
+}
+
+func (client *UserAdminClient) SetUserRole(username string, role string) (*pb.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.SetUserRole(ctx, &pb.SetUserRoleRequest{Username: username, Role: role})
+	if err != nil {
+		return nil, fmt.Errorf("cannot set user role: %w", err)
+	}
+	return res.GetUser(), nil
+}
+
+// DisableUser keeps the user from logging in and revokes their tokens
+func (client *UserAdminClient) DisableUser(username string) (*pb.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.DisableUser(ctx, &pb.DisableUserRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("cannot disable user: %w", err)
+	}
+	return res.GetUser(), nil
+}
+
+func (client *UserAdminClient) EnableUser(username string) (*pb.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.EnableUser(ctx, &pb.EnableUserRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("cannot enable user: %w", err)
+	}
+	return res.GetUser(), nil
+}
+
+func (client *UserAdminClient) DeleteUser(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.service.DeleteUser(ctx, &pb.DeleteUserRequest{Username: username})
+	if err != nil {
+		return fmt.Errorf("cannot delete user: %w", err)
+	}
+	return nil
+}
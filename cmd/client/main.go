@@ -39,7 +39,11 @@ func main() {
 		log.Fatal("cannot connect to grpc server: ", err)
 	}
 
-	authClient := client.NewAuthClient(conn1, username, password)
+	authClient := client.NewAuthClient(conn1)
+	_, err = authClient.Login(username, password)
+	if err != nil {
+		log.Fatal("cannot login: ", err)
+	}
 	authInterceptor, err := client.NewAuthInterceptor(authClient, authMethods(), refreshDuration)
 	if err != nil {
 		log.Fatal("cannot create AuthInterceptor: ", err)
@@ -57,11 +61,30 @@ func main() {
 
 func authMethods() map[string]bool {
 	const laptopServicePath = "/grpc.go.LaptopService/"
+	const reviewServicePath = "/grpc.go.ReviewService/"
+	const authServicePath = "/grpc.go.AuthService/"
+	const userAdminServicePath = "/grpc.go.UserAdminService/"
 
 	return map[string]bool{
-		laptopServicePath + "CreateLaptop": true,
-		laptopServicePath + "UploadImage":  true,
-		laptopServicePath + "RateLaptop":   true,
+		laptopServicePath + "CreateLaptop":        true,
+		laptopServicePath + "UpdateLaptop":        true,
+		laptopServicePath + "DeleteLaptop":        true,
+		laptopServicePath + "UploadImage":         true,
+		laptopServicePath + "GetUploadStatus":     true,
+		laptopServicePath + "CollectImageGarbage": true,
+		laptopServicePath + "RateLaptop":          true,
+		laptopServicePath + "RetractRating":       true,
+		reviewServicePath + "CreateReview":        true,
+		reviewServicePath + "VoteReviewHelpful":   true,
+		reviewServicePath + "SetReviewStatus":     true,
+		authServicePath + "RevokeUserTokens":      true,
+		authServicePath + "ChangePassword":        true,
+		userAdminServicePath + "ListUsers":        true,
+		userAdminServicePath + "GetUser":          true,
+		userAdminServicePath + "SetUserRole":      true,
+		userAdminServicePath + "DisableUser":      true,
+		userAdminServicePath + "EnableUser":       true,
+		userAdminServicePath + "DeleteUser":       true,
 	}
 }
 
@@ -84,7 +107,10 @@ func loadTLSCredentials() (credentials.TransportCredentials, error) {
 func testUploadImage(laptopClient *client.LaptopClient) {
 	laptop := sample.NewLaptop()
 	laptopClient.CreateLaptop(laptop)
-	laptopClient.UploadImage(laptop.GetId(), "tmp/laptop.jpg")
+	_, err := laptopClient.UploadImage(laptop.GetId(), "tmp/laptop.jpg")
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func testCreateLaptop(laptopClient *client.LaptopClient) {
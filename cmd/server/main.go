@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
@@ -12,16 +13,33 @@ import (
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	secretKey     = "secret"
-	tokenDuration = 15 * time.Minute
+	secretKey            = "secret"
+	tokenDuration        = 15 * time.Minute
+	refreshTokenDuration = 30 * 24 * time.Hour
 )
 
 func main() {
 	port := flag.Int("port", 0, "the server port")
+	dataDir := flag.String("data-dir", "", "the directory to persist laptops in, keep them in memory if empty")
+	renditionSizes := flag.String("rendition-sizes", "128,512,1024", "comma separated longest edges in pixels of the image renditions")
+	ratingMin := flag.Float64("rating-min", service.DefaultRatingScale.Min, "the lowest score of a rating")
+	ratingMax := flag.Float64("rating-max", service.DefaultRatingScale.Max, "the highest score of a rating")
+	ratingStep := flag.Float64("rating-step", service.DefaultRatingScale.Step, "scores are multiples of the step from the lowest score, 0 accepts any score")
+	ratingPriorMean := flag.Float64("rating-prior-mean", 0, "the score the weighted score of a laptop starts from, the middle of the rating scale if unset")
+	ratingPriorWeight := flag.Float64("rating-prior-weight", service.DefaultRatingPrior.Weight, "the number of ratings the prior mean counts as")
+	jwtAlgorithm := flag.String("jwt-algorithm", "RS256", "the algorithm access tokens are signed with: RS256, ES256, EdDSA, or HS256 with the shared secret")
+	jwtKeyRotation := flag.Duration("jwt-key-rotation", 24*time.Hour, "how often the signing key is rotated, 0 disables it")
+	jwtKeyGrace := flag.Duration("jwt-key-grace", tokenDuration, "how long a rotated key still verifies tokens, at least the token duration")
+	jwtKeyDir := flag.String("jwt-key-dir", "", "the directory the signing keys are kept in, servers sharing it accept each other's tokens; "+
+		"if empty the keys are not persisted and a restart invalidates every issued token")
+	notifyFile := flag.String("notify-file", "", "the file password reset codes are appended to, write them to the log if empty")
+	imageGCInterval := flag.Duration("image-gc-interval", time.Hour, "how often orphaned image files are removed, 0 disables it, it only runs with a data dir")
 	flag.Parse()
 	log.Printf("start server on port %d", *port)
 
@@ -30,18 +48,46 @@ func main() {
 		log.Fatalf("cannot load TLS credentials: %v", err)
 	}
 
-	jwtManager := service.NewJWTManager(secretKey, tokenDuration)
-	authInterceptor := service.NewAuthInterceptor(jwtManager, accessibleRoles())
+	jwtManager, err := newJWTManager(*jwtAlgorithm, *jwtKeyDir, *jwtKeyRotation, *jwtKeyGrace)
+	if err != nil {
+		log.Fatal("cannot create jwt manager: ", err)
+	}
+	revocationList := service.NewInMemoryRevocationList()
+	authInterceptor := service.NewAuthInterceptor(jwtManager, revocationList, accessibleRoles())
 	grpcServer := grpc.NewServer(
 		grpc.Creds(tlsCredentials),
 		grpc.UnaryInterceptor(authInterceptor.Unary()),
 		grpc.StreamInterceptor(authInterceptor.Stream()))
 	// laptopServer
-	laptopStore := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore("tmp" + string(os.PathSeparator) + "img")
-	ratingStore := service.NewInMemoryRatingStore()
-	laptopServer := service.NewLaptopServer(laptopStore, imageStore, ratingStore)
+	laptopStore, err := newLaptopStore(*dataDir)
+	if err != nil {
+		log.Fatal("cannot create laptop store: ", err)
+	}
+	sizes, err := parseRenditionSizes(*renditionSizes)
+	if err != nil {
+		log.Fatal("cannot parse rendition sizes: ", err)
+	}
+	imageStore, err := service.NewDiskImageStoreWithRenditions("tmp"+string(os.PathSeparator)+"img", sizes)
+	if err != nil {
+		log.Fatal("cannot create image store: ", err)
+	}
+	// the images outlive laptops kept in memory, collecting them after a restart would delete all of them
+	if *imageGCInterval > 0 && *dataDir != "" {
+		go service.RunImageGC(context.Background(), imageStore, laptopStore, *imageGCInterval)
+	}
+	ratings, err := newRatingConfig(*ratingMin, *ratingMax, *ratingStep, *ratingPriorMean, *ratingPriorWeight)
+	if err != nil {
+		log.Fatal("invalid rating flags: ", err)
+	}
+	ratingStore := service.NewInMemoryRatingStoreWithScale(ratings.Scale)
+	laptopServer := service.NewLaptopServerWithRatingConfig(laptopStore, imageStore, ratingStore, ratings)
+	if *dataDir != "" {
+		laptopServer.EnableImageGC()
+	}
 	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	// reviewServer
+	reviewServer := service.NewReviewServer(service.NewInMemoryReviewStore(), laptopStore, ratingStore, ratings.Scale)
+	pb.RegisterReviewServiceServer(grpcServer, reviewServer)
 	// authServer
 	userStore := service.NewInMemoryUserStore()
 	err = seedUsers(userStore)
@@ -49,8 +95,24 @@ func main() {
 		log.Fatal("cannot seed users: ", err)
 	}
 
-	authServer := service.NewAuthServer(userStore, jwtManager)
+	notifier := service.NewLogNotifier()
+	if *notifyFile != "" {
+		notifier = service.NewFileNotifier(*notifyFile)
+	}
+	refreshTokenStore := service.NewInMemoryRefreshTokenStore()
+	authServer := service.NewAuthServerWithPasswordReset(
+		userStore,
+		jwtManager,
+		refreshTokenStore,
+		refreshTokenDuration,
+		revocationList,
+		service.NewInMemoryPasswordResetStore(),
+		notifier,
+	)
 	pb.RegisterAuthServiceServer(grpcServer, authServer)
+	// userAdminServer
+	userAdminServer := service.NewUserAdminServer(userStore, jwtManager, refreshTokenStore, revocationList)
+	pb.RegisterUserAdminServiceServer(grpcServer, userAdminServer)
 	reflection.Register(grpcServer)
 
 	address := fmt.Sprintf("127.0.0.1:%d", *port)
@@ -64,6 +126,76 @@ func main() {
 	}
 }
 
+func newJWTManager(algorithm string, keyDir string, keyRotation time.Duration, keyGrace time.Duration) (*service.JWTManager, error) {
+	if algorithm == "HS256" {
+		return service.NewJWTManager(secretKey, tokenDuration), nil
+	}
+	if keyGrace < tokenDuration {
+		return nil, fmt.Errorf("key grace period %v is shorter than the token duration %v", keyGrace, tokenDuration)
+	}
+	var keys *service.SigningKeyRing
+	var err error
+	if keyDir == "" {
+		log.Print("signing keys are kept in memory, a restart invalidates the issued tokens")
+		keys, err = service.NewSigningKeyRing(algorithm, keyGrace)
+	} else {
+		log.Printf("keep signing keys in %s", keyDir)
+		keys, err = service.OpenSigningKeyRing(keyDir, algorithm, keyGrace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if keyRotation > 0 {
+		go service.RunKeyRotation(context.Background(), keys, keyRotation)
+	}
+	return service.NewJWTManagerWithKeys(keys, tokenDuration), nil
+}
+
+// newRatingConfig returns the rating config of the flags, the prior mean defaults to the middle of the scale
+func newRatingConfig(min float64, max float64, step float64, priorMean float64, priorWeight float64) (service.RatingConfig, error) {
+	scale := service.RatingScale{Min: min, Max: max, Step: step}
+	priorMeanSet := false
+	flag.Visit(func(f *flag.Flag) {
+		priorMeanSet = priorMeanSet || f.Name == "rating-prior-mean"
+	})
+	if !priorMeanSet {
+		priorMean = scale.Middle()
+	}
+	ratings := service.RatingConfig{
+		Scale: scale,
+		Prior: service.RatingPrior{Mean: priorMean, Weight: priorWeight},
+	}
+	return ratings, ratings.Check()
+}
+
+func newLaptopStore(dataDir string) (service.LaptopStore, error) {
+	if dataDir == "" {
+		return service.NewWatchableLaptopStore(service.NewInMemoryLaptopStore()), nil
+	}
+	log.Printf("persist laptops in %s", dataDir)
+	store, err := service.NewFileLaptopStore(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewWatchableLaptopStore(store), nil
+}
+
+func parseRenditionSizes(value string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
 func createUser(userStore service.UserStore, username, password, role string) error {
 	user, err := service.NewUser(username, password, role)
 	if err != nil {
@@ -82,10 +214,30 @@ func seedUsers(userStore service.UserStore) error {
 
 func accessibleRoles() map[string][]string {
 	const laptopServicePath = "/grpc.go.LaptopService/"
+	const reviewServicePath = "/grpc.go.ReviewService/"
+	const authServicePath = "/grpc.go.AuthService/"
+	const userAdminServicePath = "/grpc.go.UserAdminService/"
 	return map[string][]string{
-		laptopServicePath + "CreateLaptop": {"admin"},
-		laptopServicePath + "UploadImage":  {"admin"},
-		laptopServicePath + "RateLaptop":   {"admin", "user"},
+		laptopServicePath + "CreateLaptop":        {"admin"},
+		laptopServicePath + "UpdateLaptop":        {"admin"},
+		laptopServicePath + "DeleteLaptop":        {"admin"},
+		laptopServicePath + "UploadImage":         {"admin"},
+		laptopServicePath + "GetUploadStatus":     {"admin"},
+		laptopServicePath + "CollectImageGarbage": {"admin"},
+		laptopServicePath + "RateLaptop":          {"admin", "user"},
+		laptopServicePath + "RetractRating":       {"admin", "user"},
+		reviewServicePath + "CreateReview":        {"admin", "user"},
+		reviewServicePath + "VoteReviewHelpful":   {"admin", "user"},
+		reviewServicePath + "SetReviewStatus":     {"admin"},
+		authServicePath + "Logout":                {"admin", "user"},
+		authServicePath + "RevokeUserTokens":      {"admin"},
+		authServicePath + "ChangePassword":        {"admin", "user"},
+		userAdminServicePath + "ListUsers":        {"admin"},
+		userAdminServicePath + "GetUser":          {"admin"},
+		userAdminServicePath + "SetUserRole":      {"admin"},
+		userAdminServicePath + "DisableUser":      {"admin"},
+		userAdminServicePath + "EnableUser":       {"admin"},
+		userAdminServicePath + "DeleteUser":       {"admin"},
 	}
 }
 
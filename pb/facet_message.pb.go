@@ -0,0 +1,503 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.5
+// source: facet_message.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// FacetSpec selects the facets computed over the laptops matching a filter
+type FacetSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// value_fields are counted per value, one of brand, name, cpu.brand, gpu.brand,
+	// screen.panel, keyboard.layout, release_year or price_usd (counted per price bucket)
+	ValueFields []string `protobuf:"bytes,1,rep,name=value_fields,json=valueFields,proto3" json:"value_fields,omitempty"`
+	// numeric_fields get min/max/avg, one of price_usd, release_year, cpu.number_cores,
+	// cpu.min_ghz, ram_gb, weight_kg or screen.size_inch
+	NumericFields []string `protobuf:"bytes,2,rep,name=numeric_fields,json=numericFields,proto3" json:"numeric_fields,omitempty"`
+	// price_bucket_size is the width of the price_usd buckets, 500 if unset
+	PriceBucketSize float64 `protobuf:"fixed64,3,opt,name=price_bucket_size,json=priceBucketSize,proto3" json:"price_bucket_size,omitempty"`
+}
+
+func (x *FacetSpec) Reset() {
+	*x = FacetSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_facet_message_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FacetSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacetSpec) ProtoMessage() {}
+
+func (x *FacetSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_facet_message_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacetSpec.ProtoReflect.Descriptor instead.
+func (*FacetSpec) Descriptor() ([]byte, []int) {
+	return file_facet_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FacetSpec) GetValueFields() []string {
+	if x != nil {
+		return x.ValueFields
+	}
+	return nil
+}
+
+func (x *FacetSpec) GetNumericFields() []string {
+	if x != nil {
+		return x.NumericFields
+	}
+	return nil
+}
+
+func (x *FacetSpec) GetPriceBucketSize() float64 {
+	if x != nil {
+		return x.PriceBucketSize
+	}
+	return 0
+}
+
+type ValueFacet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// counts are sorted by descending count
+	Counts []*ValueFacet_Count `protobuf:"bytes,2,rep,name=counts,proto3" json:"counts,omitempty"`
+}
+
+func (x *ValueFacet) Reset() {
+	*x = ValueFacet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_facet_message_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValueFacet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueFacet) ProtoMessage() {}
+
+func (x *ValueFacet) ProtoReflect() protoreflect.Message {
+	mi := &file_facet_message_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueFacet.ProtoReflect.Descriptor instead.
+func (*ValueFacet) Descriptor() ([]byte, []int) {
+	return file_facet_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ValueFacet) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ValueFacet) GetCounts() []*ValueFacet_Count {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type NumericFacet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field string  `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Count uint64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Min   float64 `protobuf:"fixed64,3,opt,name=min,proto3" json:"min,omitempty"`
+	Max   float64 `protobuf:"fixed64,4,opt,name=max,proto3" json:"max,omitempty"`
+	Avg   float64 `protobuf:"fixed64,5,opt,name=avg,proto3" json:"avg,omitempty"`
+}
+
+func (x *NumericFacet) Reset() {
+	*x = NumericFacet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_facet_message_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NumericFacet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumericFacet) ProtoMessage() {}
+
+func (x *NumericFacet) ProtoReflect() protoreflect.Message {
+	mi := &file_facet_message_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumericFacet.ProtoReflect.Descriptor instead.
+func (*NumericFacet) Descriptor() ([]byte, []int) {
+	return file_facet_message_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NumericFacet) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *NumericFacet) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *NumericFacet) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *NumericFacet) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *NumericFacet) GetAvg() float64 {
+	if x != nil {
+		return x.Avg
+	}
+	return 0
+}
+
+type Facets struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Total         uint64          `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	ValueFacets   []*ValueFacet   `protobuf:"bytes,2,rep,name=value_facets,json=valueFacets,proto3" json:"value_facets,omitempty"`
+	NumericFacets []*NumericFacet `protobuf:"bytes,3,rep,name=numeric_facets,json=numericFacets,proto3" json:"numeric_facets,omitempty"`
+}
+
+func (x *Facets) Reset() {
+	*x = Facets{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_facet_message_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Facets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Facets) ProtoMessage() {}
+
+func (x *Facets) ProtoReflect() protoreflect.Message {
+	mi := &file_facet_message_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Facets.ProtoReflect.Descriptor instead.
+func (*Facets) Descriptor() ([]byte, []int) {
+	return file_facet_message_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Facets) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Facets) GetValueFacets() []*ValueFacet {
+	if x != nil {
+		return x.ValueFacets
+	}
+	return nil
+}
+
+func (x *Facets) GetNumericFacets() []*NumericFacet {
+	if x != nil {
+		return x.NumericFacets
+	}
+	return nil
+}
+
+type ValueFacet_Count struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count uint64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *ValueFacet_Count) Reset() {
+	*x = ValueFacet_Count{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_facet_message_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValueFacet_Count) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueFacet_Count) ProtoMessage() {}
+
+func (x *ValueFacet_Count) ProtoReflect() protoreflect.Message {
+	mi := &file_facet_message_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueFacet_Count.ProtoReflect.Descriptor instead.
+func (*ValueFacet_Count) Descriptor() ([]byte, []int) {
+	return file_facet_message_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *ValueFacet_Count) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ValueFacet_Count) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+var File_facet_message_proto protoreflect.FileDescriptor
+
+var file_facet_message_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x66, 0x61, 0x63, 0x65, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x67, 0x6f, 0x22, 0x81,
+	0x01, 0x0a, 0x09, 0x46, 0x61, 0x63, 0x65, 0x74, 0x53, 0x70, 0x65, 0x63, 0x12, 0x21, 0x0a, 0x0c,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f,
+	0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x53, 0x69,
+	0x7a, 0x65, 0x22, 0x8a, 0x01, 0x0a, 0x0a, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x61, 0x63, 0x65,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x31, 0x0a, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x67,
+	0x6f, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x61, 0x63, 0x65, 0x74, 0x2e, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x52, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x1a, 0x33, 0x0a, 0x05, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22,
+	0x70, 0x0a, 0x0c, 0x4e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x46, 0x61, 0x63, 0x65, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6d,
+	0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x69, 0x6e, 0x12, 0x10, 0x0a,
+	0x03, 0x6d, 0x61, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x61, 0x78, 0x12,
+	0x10, 0x0a, 0x03, 0x61, 0x76, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x61, 0x76,
+	0x67, 0x22, 0x94, 0x01, 0x0a, 0x06, 0x46, 0x61, 0x63, 0x65, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x12, 0x36, 0x0a, 0x0c, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x66, 0x61, 0x63, 0x65,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x2e,
+	0x67, 0x6f, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x61, 0x63, 0x65, 0x74, 0x52, 0x0b, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x46, 0x61, 0x63, 0x65, 0x74, 0x73, 0x12, 0x3c, 0x0a, 0x0e, 0x6e, 0x75,
+	0x6d, 0x65, 0x72, 0x69, 0x63, 0x5f, 0x66, 0x61, 0x63, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x67, 0x6f, 0x2e, 0x4e, 0x75, 0x6d,
+	0x65, 0x72, 0x69, 0x63, 0x46, 0x61, 0x63, 0x65, 0x74, 0x52, 0x0d, 0x6e, 0x75, 0x6d, 0x65, 0x72,
+	0x69, 0x63, 0x46, 0x61, 0x63, 0x65, 0x74, 0x73, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_facet_message_proto_rawDescOnce sync.Once
+	file_facet_message_proto_rawDescData = file_facet_message_proto_rawDesc
+)
+
+func file_facet_message_proto_rawDescGZIP() []byte {
+	file_facet_message_proto_rawDescOnce.Do(func() {
+		file_facet_message_proto_rawDescData = protoimpl.X.CompressGZIP(file_facet_message_proto_rawDescData)
+	})
+	return file_facet_message_proto_rawDescData
+}
+
+var file_facet_message_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_facet_message_proto_goTypes = []interface{}{
+	(*FacetSpec)(nil),        // 0: grpc.go.FacetSpec
+	(*ValueFacet)(nil),       // 1: grpc.go.ValueFacet
+	(*NumericFacet)(nil),     // 2: grpc.go.NumericFacet
+	(*Facets)(nil),           // 3: grpc.go.Facets
+	(*ValueFacet_Count)(nil), // 4: grpc.go.ValueFacet.Count
+}
+var file_facet_message_proto_depIdxs = []int32{
+	4, // 0: grpc.go.ValueFacet.counts:type_name -> grpc.go.ValueFacet.Count
+	1, // 1: grpc.go.Facets.value_facets:type_name -> grpc.go.ValueFacet
+	2, // 2: grpc.go.Facets.numeric_facets:type_name -> grpc.go.NumericFacet
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_facet_message_proto_init() }
+func file_facet_message_proto_init() {
+	if File_facet_message_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_facet_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FacetSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_facet_message_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValueFacet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_facet_message_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NumericFacet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_facet_message_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Facets); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_facet_message_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValueFacet_Count); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_facet_message_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_facet_message_proto_goTypes,
+		DependencyIndexes: file_facet_message_proto_depIdxs,
+		MessageInfos:      file_facet_message_proto_msgTypes,
+	}.Build()
+	File_facet_message_proto = out.File
+	file_facet_message_proto_rawDesc = nil
+	file_facet_message_proto_goTypes = nil
+	file_facet_message_proto_depIdxs = nil
+}
@@ -23,9 +23,22 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type LaptopServiceClient interface {
 	CreateLaptop(ctx context.Context, in *CreateLaptopRequest, opts ...grpc.CallOption) (*CreateLaptopResponse, error)
+	GetLaptop(ctx context.Context, in *GetLaptopRequest, opts ...grpc.CallOption) (*GetLaptopResponse, error)
+	BatchGetLaptops(ctx context.Context, in *BatchGetLaptopsRequest, opts ...grpc.CallOption) (*BatchGetLaptopsResponse, error)
+	UpdateLaptop(ctx context.Context, in *UpdateLaptopRequest, opts ...grpc.CallOption) (*UpdateLaptopResponse, error)
+	DeleteLaptop(ctx context.Context, in *DeleteLaptopRequest, opts ...grpc.CallOption) (*DeleteLaptopResponse, error)
 	SearchLaptop(ctx context.Context, in *SearchLaptopRequest, opts ...grpc.CallOption) (LaptopService_SearchLaptopClient, error)
+	GetLaptopFacets(ctx context.Context, in *GetLaptopFacetsRequest, opts ...grpc.CallOption) (*GetLaptopFacetsResponse, error)
+	WatchLaptops(ctx context.Context, in *WatchLaptopsRequest, opts ...grpc.CallOption) (LaptopService_WatchLaptopsClient, error)
 	UploadImage(ctx context.Context, opts ...grpc.CallOption) (LaptopService_UploadImageClient, error)
+	GetUploadStatus(ctx context.Context, in *GetUploadStatusRequest, opts ...grpc.CallOption) (*GetUploadStatusResponse, error)
+	ListImages(ctx context.Context, in *ListImagesRequest, opts ...grpc.CallOption) (*ListImagesResponse, error)
+	DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (LaptopService_DownloadImageClient, error)
+	CollectImageGarbage(ctx context.Context, in *CollectImageGarbageRequest, opts ...grpc.CallOption) (*CollectImageGarbageResponse, error)
 	RateLaptop(ctx context.Context, opts ...grpc.CallOption) (LaptopService_RateLaptopClient, error)
+	RetractRating(ctx context.Context, in *RetractRatingRequest, opts ...grpc.CallOption) (*RetractRatingResponse, error)
+	GetLaptopRating(ctx context.Context, in *GetLaptopRatingRequest, opts ...grpc.CallOption) (*GetLaptopRatingResponse, error)
+	ListTopRatedLaptops(ctx context.Context, in *ListTopRatedLaptopsRequest, opts ...grpc.CallOption) (*ListTopRatedLaptopsResponse, error)
 }
 
 type laptopServiceClient struct {
@@ -45,6 +58,42 @@ func (c *laptopServiceClient) CreateLaptop(ctx context.Context, in *CreateLaptop
 	return out, nil
 }
 
+func (c *laptopServiceClient) GetLaptop(ctx context.Context, in *GetLaptopRequest, opts ...grpc.CallOption) (*GetLaptopResponse, error) {
+	out := new(GetLaptopResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/GetLaptop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) BatchGetLaptops(ctx context.Context, in *BatchGetLaptopsRequest, opts ...grpc.CallOption) (*BatchGetLaptopsResponse, error) {
+	out := new(BatchGetLaptopsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/BatchGetLaptops", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) UpdateLaptop(ctx context.Context, in *UpdateLaptopRequest, opts ...grpc.CallOption) (*UpdateLaptopResponse, error) {
+	out := new(UpdateLaptopResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/UpdateLaptop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) DeleteLaptop(ctx context.Context, in *DeleteLaptopRequest, opts ...grpc.CallOption) (*DeleteLaptopResponse, error) {
+	out := new(DeleteLaptopResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/DeleteLaptop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *laptopServiceClient) SearchLaptop(ctx context.Context, in *SearchLaptopRequest, opts ...grpc.CallOption) (LaptopService_SearchLaptopClient, error) {
 	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[0], "/grpc.go.LaptopService/SearchLaptop", opts...)
 	if err != nil {
@@ -77,8 +126,49 @@ func (x *laptopServiceSearchLaptopClient) Recv() (*SearchLaptopResponse, error)
 	return m, nil
 }
 
+func (c *laptopServiceClient) GetLaptopFacets(ctx context.Context, in *GetLaptopFacetsRequest, opts ...grpc.CallOption) (*GetLaptopFacetsResponse, error) {
+	out := new(GetLaptopFacetsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/GetLaptopFacets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) WatchLaptops(ctx context.Context, in *WatchLaptopsRequest, opts ...grpc.CallOption) (LaptopService_WatchLaptopsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[1], "/grpc.go.LaptopService/WatchLaptops", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceWatchLaptopsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LaptopService_WatchLaptopsClient interface {
+	Recv() (*WatchLaptopsResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceWatchLaptopsClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceWatchLaptopsClient) Recv() (*WatchLaptopsResponse, error) {
+	m := new(WatchLaptopsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *laptopServiceClient) UploadImage(ctx context.Context, opts ...grpc.CallOption) (LaptopService_UploadImageClient, error) {
-	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[1], "/grpc.go.LaptopService/UploadImage", opts...)
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[2], "/grpc.go.LaptopService/UploadImage", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +201,67 @@ func (x *laptopServiceUploadImageClient) CloseAndRecv() (*UploadImageResponse, e
 	return m, nil
 }
 
+func (c *laptopServiceClient) GetUploadStatus(ctx context.Context, in *GetUploadStatusRequest, opts ...grpc.CallOption) (*GetUploadStatusResponse, error) {
+	out := new(GetUploadStatusResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/GetUploadStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) ListImages(ctx context.Context, in *ListImagesRequest, opts ...grpc.CallOption) (*ListImagesResponse, error) {
+	out := new(ListImagesResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/ListImages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (LaptopService_DownloadImageClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[3], "/grpc.go.LaptopService/DownloadImage", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &laptopServiceDownloadImageClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LaptopService_DownloadImageClient interface {
+	Recv() (*DownloadImageResponse, error)
+	grpc.ClientStream
+}
+
+type laptopServiceDownloadImageClient struct {
+	grpc.ClientStream
+}
+
+func (x *laptopServiceDownloadImageClient) Recv() (*DownloadImageResponse, error) {
+	m := new(DownloadImageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *laptopServiceClient) CollectImageGarbage(ctx context.Context, in *CollectImageGarbageRequest, opts ...grpc.CallOption) (*CollectImageGarbageResponse, error) {
+	out := new(CollectImageGarbageResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/CollectImageGarbage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *laptopServiceClient) RateLaptop(ctx context.Context, opts ...grpc.CallOption) (LaptopService_RateLaptopClient, error) {
-	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[2], "/grpc.go.LaptopService/RateLaptop", opts...)
+	stream, err := c.cc.NewStream(ctx, &LaptopService_ServiceDesc.Streams[4], "/grpc.go.LaptopService/RateLaptop", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -142,14 +291,54 @@ func (x *laptopServiceRateLaptopClient) Recv() (*RateLaptopResponse, error) {
 	return m, nil
 }
 
+func (c *laptopServiceClient) RetractRating(ctx context.Context, in *RetractRatingRequest, opts ...grpc.CallOption) (*RetractRatingResponse, error) {
+	out := new(RetractRatingResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/RetractRating", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) GetLaptopRating(ctx context.Context, in *GetLaptopRatingRequest, opts ...grpc.CallOption) (*GetLaptopRatingResponse, error) {
+	out := new(GetLaptopRatingResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/GetLaptopRating", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laptopServiceClient) ListTopRatedLaptops(ctx context.Context, in *ListTopRatedLaptopsRequest, opts ...grpc.CallOption) (*ListTopRatedLaptopsResponse, error) {
+	out := new(ListTopRatedLaptopsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.go.LaptopService/ListTopRatedLaptops", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LaptopServiceServer is the server API for LaptopService service.
 // All implementations must embed UnimplementedLaptopServiceServer
 // for forward compatibility
 type LaptopServiceServer interface {
 	CreateLaptop(context.Context, *CreateLaptopRequest) (*CreateLaptopResponse, error)
+	GetLaptop(context.Context, *GetLaptopRequest) (*GetLaptopResponse, error)
+	BatchGetLaptops(context.Context, *BatchGetLaptopsRequest) (*BatchGetLaptopsResponse, error)
+	UpdateLaptop(context.Context, *UpdateLaptopRequest) (*UpdateLaptopResponse, error)
+	DeleteLaptop(context.Context, *DeleteLaptopRequest) (*DeleteLaptopResponse, error)
 	SearchLaptop(*SearchLaptopRequest, LaptopService_SearchLaptopServer) error
+	GetLaptopFacets(context.Context, *GetLaptopFacetsRequest) (*GetLaptopFacetsResponse, error)
+	WatchLaptops(*WatchLaptopsRequest, LaptopService_WatchLaptopsServer) error
 	UploadImage(LaptopService_UploadImageServer) error
+	GetUploadStatus(context.Context, *GetUploadStatusRequest) (*GetUploadStatusResponse, error)
+	ListImages(context.Context, *ListImagesRequest) (*ListImagesResponse, error)
+	DownloadImage(*DownloadImageRequest, LaptopService_DownloadImageServer) error
+	CollectImageGarbage(context.Context, *CollectImageGarbageRequest) (*CollectImageGarbageResponse, error)
 	RateLaptop(LaptopService_RateLaptopServer) error
+	RetractRating(context.Context, *RetractRatingRequest) (*RetractRatingResponse, error)
+	GetLaptopRating(context.Context, *GetLaptopRatingRequest) (*GetLaptopRatingResponse, error)
+	ListTopRatedLaptops(context.Context, *ListTopRatedLaptopsRequest) (*ListTopRatedLaptopsResponse, error)
 	mustEmbedUnimplementedLaptopServiceServer()
 }
 
@@ -160,15 +349,54 @@ type UnimplementedLaptopServiceServer struct {
 func (UnimplementedLaptopServiceServer) CreateLaptop(context.Context, *CreateLaptopRequest) (*CreateLaptopResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateLaptop not implemented")
 }
+func (UnimplementedLaptopServiceServer) GetLaptop(context.Context, *GetLaptopRequest) (*GetLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLaptop not implemented")
+}
+func (UnimplementedLaptopServiceServer) BatchGetLaptops(context.Context, *BatchGetLaptopsRequest) (*BatchGetLaptopsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetLaptops not implemented")
+}
+func (UnimplementedLaptopServiceServer) UpdateLaptop(context.Context, *UpdateLaptopRequest) (*UpdateLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLaptop not implemented")
+}
+func (UnimplementedLaptopServiceServer) DeleteLaptop(context.Context, *DeleteLaptopRequest) (*DeleteLaptopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLaptop not implemented")
+}
 func (UnimplementedLaptopServiceServer) SearchLaptop(*SearchLaptopRequest, LaptopService_SearchLaptopServer) error {
 	return status.Errorf(codes.Unimplemented, "method SearchLaptop not implemented")
 }
+func (UnimplementedLaptopServiceServer) GetLaptopFacets(context.Context, *GetLaptopFacetsRequest) (*GetLaptopFacetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLaptopFacets not implemented")
+}
+func (UnimplementedLaptopServiceServer) WatchLaptops(*WatchLaptopsRequest, LaptopService_WatchLaptopsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLaptops not implemented")
+}
 func (UnimplementedLaptopServiceServer) UploadImage(LaptopService_UploadImageServer) error {
 	return status.Errorf(codes.Unimplemented, "method UploadImage not implemented")
 }
+func (UnimplementedLaptopServiceServer) GetUploadStatus(context.Context, *GetUploadStatusRequest) (*GetUploadStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadStatus not implemented")
+}
+func (UnimplementedLaptopServiceServer) ListImages(context.Context, *ListImagesRequest) (*ListImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListImages not implemented")
+}
+func (UnimplementedLaptopServiceServer) DownloadImage(*DownloadImageRequest, LaptopService_DownloadImageServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadImage not implemented")
+}
+func (UnimplementedLaptopServiceServer) CollectImageGarbage(context.Context, *CollectImageGarbageRequest) (*CollectImageGarbageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectImageGarbage not implemented")
+}
 func (UnimplementedLaptopServiceServer) RateLaptop(LaptopService_RateLaptopServer) error {
 	return status.Errorf(codes.Unimplemented, "method RateLaptop not implemented")
 }
+func (UnimplementedLaptopServiceServer) RetractRating(context.Context, *RetractRatingRequest) (*RetractRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetractRating not implemented")
+}
+func (UnimplementedLaptopServiceServer) GetLaptopRating(context.Context, *GetLaptopRatingRequest) (*GetLaptopRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLaptopRating not implemented")
+}
+func (UnimplementedLaptopServiceServer) ListTopRatedLaptops(context.Context, *ListTopRatedLaptopsRequest) (*ListTopRatedLaptopsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTopRatedLaptops not implemented")
+}
 func (UnimplementedLaptopServiceServer) mustEmbedUnimplementedLaptopServiceServer() {}
 
 // UnsafeLaptopServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -200,6 +428,78 @@ func _LaptopService_CreateLaptop_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LaptopService_GetLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).GetLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/GetLaptop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).GetLaptop(ctx, req.(*GetLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_BatchGetLaptops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetLaptopsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).BatchGetLaptops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/BatchGetLaptops",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).BatchGetLaptops(ctx, req.(*BatchGetLaptopsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_UpdateLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).UpdateLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/UpdateLaptop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).UpdateLaptop(ctx, req.(*UpdateLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_DeleteLaptop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLaptopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).DeleteLaptop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/DeleteLaptop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).DeleteLaptop(ctx, req.(*DeleteLaptopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LaptopService_SearchLaptop_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(SearchLaptopRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -221,6 +521,45 @@ func (x *laptopServiceSearchLaptopServer) Send(m *SearchLaptopResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _LaptopService_GetLaptopFacets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLaptopFacetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).GetLaptopFacets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/GetLaptopFacets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).GetLaptopFacets(ctx, req.(*GetLaptopFacetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_WatchLaptops_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLaptopsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LaptopServiceServer).WatchLaptops(m, &laptopServiceWatchLaptopsServer{stream})
+}
+
+type LaptopService_WatchLaptopsServer interface {
+	Send(*WatchLaptopsResponse) error
+	grpc.ServerStream
+}
+
+type laptopServiceWatchLaptopsServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceWatchLaptopsServer) Send(m *WatchLaptopsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _LaptopService_UploadImage_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LaptopServiceServer).UploadImage(&laptopServiceUploadImageServer{stream})
 }
@@ -247,6 +586,81 @@ func (x *laptopServiceUploadImageServer) Recv() (*UploadImageRequest, error) {
 	return m, nil
 }
 
+func _LaptopService_GetUploadStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).GetUploadStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/GetUploadStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).GetUploadStatus(ctx, req.(*GetUploadStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_ListImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).ListImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/ListImages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).ListImages(ctx, req.(*ListImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_DownloadImage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LaptopServiceServer).DownloadImage(m, &laptopServiceDownloadImageServer{stream})
+}
+
+type LaptopService_DownloadImageServer interface {
+	Send(*DownloadImageResponse) error
+	grpc.ServerStream
+}
+
+type laptopServiceDownloadImageServer struct {
+	grpc.ServerStream
+}
+
+func (x *laptopServiceDownloadImageServer) Send(m *DownloadImageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LaptopService_CollectImageGarbage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectImageGarbageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).CollectImageGarbage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/CollectImageGarbage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).CollectImageGarbage(ctx, req.(*CollectImageGarbageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LaptopService_RateLaptop_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LaptopServiceServer).RateLaptop(&laptopServiceRateLaptopServer{stream})
 }
@@ -273,6 +687,60 @@ func (x *laptopServiceRateLaptopServer) Recv() (*RateLaptopRequest, error) {
 	return m, nil
 }
 
+func _LaptopService_RetractRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetractRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).RetractRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/RetractRating",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).RetractRating(ctx, req.(*RetractRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_GetLaptopRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLaptopRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).GetLaptopRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/GetLaptopRating",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).GetLaptopRating(ctx, req.(*GetLaptopRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaptopService_ListTopRatedLaptops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopRatedLaptopsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaptopServiceServer).ListTopRatedLaptops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.go.LaptopService/ListTopRatedLaptops",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaptopServiceServer).ListTopRatedLaptops(ctx, req.(*ListTopRatedLaptopsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LaptopService_ServiceDesc is the grpc.ServiceDesc for LaptopService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -284,6 +752,50 @@ var LaptopService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateLaptop",
 			Handler:    _LaptopService_CreateLaptop_Handler,
 		},
+		{
+			MethodName: "GetLaptop",
+			Handler:    _LaptopService_GetLaptop_Handler,
+		},
+		{
+			MethodName: "BatchGetLaptops",
+			Handler:    _LaptopService_BatchGetLaptops_Handler,
+		},
+		{
+			MethodName: "UpdateLaptop",
+			Handler:    _LaptopService_UpdateLaptop_Handler,
+		},
+		{
+			MethodName: "DeleteLaptop",
+			Handler:    _LaptopService_DeleteLaptop_Handler,
+		},
+		{
+			MethodName: "GetLaptopFacets",
+			Handler:    _LaptopService_GetLaptopFacets_Handler,
+		},
+		{
+			MethodName: "GetUploadStatus",
+			Handler:    _LaptopService_GetUploadStatus_Handler,
+		},
+		{
+			MethodName: "ListImages",
+			Handler:    _LaptopService_ListImages_Handler,
+		},
+		{
+			MethodName: "CollectImageGarbage",
+			Handler:    _LaptopService_CollectImageGarbage_Handler,
+		},
+		{
+			MethodName: "RetractRating",
+			Handler:    _LaptopService_RetractRating_Handler,
+		},
+		{
+			MethodName: "GetLaptopRating",
+			Handler:    _LaptopService_GetLaptopRating_Handler,
+		},
+		{
+			MethodName: "ListTopRatedLaptops",
+			Handler:    _LaptopService_ListTopRatedLaptops_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -291,11 +803,21 @@ var LaptopService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _LaptopService_SearchLaptop_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchLaptops",
+			Handler:       _LaptopService_WatchLaptops_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "UploadImage",
 			Handler:       _LaptopService_UploadImage_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "DownloadImage",
+			Handler:       _LaptopService_DownloadImage_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "RateLaptop",
 			Handler:       _LaptopService_RateLaptop_Handler,
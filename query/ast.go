@@ -0,0 +1,105 @@
+package query
+
+import (
+	"grpc-go/pb"
+	"strings"
+)
+
+// Expr is a parsed query that can be evaluated against a laptop
+type Expr interface {
+	Eval(laptop *pb.Laptop) bool
+}
+
+type andExpr struct {
+	left  Expr
+	right Expr
+}
+
+func (e *andExpr) Eval(laptop *pb.Laptop) bool {
+	return e.left.Eval(laptop) && e.right.Eval(laptop)
+}
+
+type orExpr struct {
+	left  Expr
+	right Expr
+}
+
+func (e *orExpr) Eval(laptop *pb.Laptop) bool {
+	return e.left.Eval(laptop) || e.right.Eval(laptop)
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+func (e *notExpr) Eval(laptop *pb.Laptop) bool {
+	return !e.expr.Eval(laptop)
+}
+
+// comparisonExpr compares a field with one literal, or with a list of literals for the in operator.
+// A field with several values (e.g. one per GPU) matches if any of them does.
+type comparisonExpr struct {
+	field    *field
+	operator string
+	literals []value
+}
+
+func (e *comparisonExpr) Eval(laptop *pb.Laptop) bool {
+	for _, v := range e.field.values(laptop) {
+		if e.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *comparisonExpr) match(v value) bool {
+	if e.operator == "in" {
+		for _, literal := range e.literals {
+			if e.field.compare(v, literal) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	c := e.field.compare(v, e.literals[0])
+	switch e.operator {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// compare returns -1, 0 or 1 like strings.Compare, strings and enums compare case-insensitively
+func (f *field) compare(a value, b value) int {
+	switch f.kind {
+	case kindNumber, kindMemory:
+		switch {
+		case a.number < b.number:
+			return -1
+		case a.number > b.number:
+			return 1
+		default:
+			return 0
+		}
+	case kindBool:
+		if a.boolean == b.boolean {
+			return 0
+		}
+		return 1
+	default:
+		return strings.Compare(strings.ToLower(a.text), strings.ToLower(b.text))
+	}
+}
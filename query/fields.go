@@ -0,0 +1,156 @@
+package query
+
+import (
+	"grpc-go/pb"
+	"grpc-go/units"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	kindNumber fieldKind = iota
+	kindMemory
+	kindString
+	kindBool
+	kindEnum
+)
+
+// value is the value of a laptop field or a literal, only the member matching the field kind is set
+type value struct {
+	number  float64
+	text    string
+	boolean bool
+}
+
+type field struct {
+	name string
+	kind fieldKind
+	// enumValues are the accepted names of an enum field
+	enumValues map[string]int32
+	// values returns every value the laptop has for the field, e.g. one per GPU
+	values func(laptop *pb.Laptop) []value
+}
+
+// memoryUnits maps the memory unit suffixes to their size in bits
+var memoryUnits = map[string]float64{
+	"BIT": 1,
+	"B":   1 << 3,
+	"KB":  1 << 13,
+	"MB":  1 << 23,
+	"GB":  1 << 33,
+	"TB":  1 << 43,
+}
+
+var fields = map[string]*field{}
+
+func init() {
+	addField("brand", kindString, nil, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetBrand())
+	})
+	addField("name", kindString, nil, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetName())
+	})
+	addField("price", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(laptop.GetPriceUsd())
+	})
+	addField("release_year", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(laptop.GetReleaseYear())
+	})
+	addField("weight", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		if laptop.GetWeight() == nil {
+			return nil
+		}
+		return numbers(units.WeightKg(laptop))
+	})
+	addField("cpu.brand", kindString, nil, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetCpu().GetBrand())
+	})
+	addField("cpu.name", kindString, nil, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetCpu().GetName())
+	})
+	addField("cpu.cores", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(laptop.GetCpu().GetNumberCores()))
+	})
+	addField("cpu.threads", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(laptop.GetCpu().GetNumberThreads()))
+	})
+	addField("cpu.ghz", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(laptop.GetCpu().GetMinGhz())
+	})
+	addField("cpu.max_ghz", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(laptop.GetCpu().GetMaxGhz())
+	})
+	addField("ram", kindMemory, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(units.ToBit(laptop.GetRam())))
+	})
+	addField("gpu.brand", kindString, nil, func(laptop *pb.Laptop) []value {
+		var values []value
+		for _, gpu := range laptop.GetGpus() {
+			values = append(values, value{text: gpu.GetBrand()})
+		}
+		return values
+	})
+	addField("gpu.name", kindString, nil, func(laptop *pb.Laptop) []value {
+		var values []value
+		for _, gpu := range laptop.GetGpus() {
+			values = append(values, value{text: gpu.GetName()})
+		}
+		return values
+	})
+	addField("gpu.memory", kindMemory, nil, func(laptop *pb.Laptop) []value {
+		var values []value
+		for _, gpu := range laptop.GetGpus() {
+			values = append(values, value{number: float64(units.ToBit(gpu.GetMemory()))})
+		}
+		return values
+	})
+	addField("ssd", kindMemory, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(units.TotalStorage(laptop, pb.Storage_SSD)))
+	})
+	addField("hdd", kindMemory, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(units.TotalStorage(laptop, pb.Storage_HDD)))
+	})
+	addField("screen.size", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(laptop.GetScreen().GetSizeInch()))
+	})
+	addField("screen.width", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(laptop.GetScreen().GetResolution().GetWidth()))
+	})
+	addField("screen.height", kindNumber, nil, func(laptop *pb.Laptop) []value {
+		return numbers(float64(laptop.GetScreen().GetResolution().GetHeight()))
+	})
+	addField("screen.panel", kindEnum, pb.Screen_Panel_value, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetScreen().GetPanel().String())
+	})
+	addField("screen.multitouch", kindBool, nil, func(laptop *pb.Laptop) []value {
+		return []value{{boolean: laptop.GetScreen().GetMultitouch()}}
+	})
+	addField("keyboard.layout", kindEnum, pb.Keyboard_Layout_value, func(laptop *pb.Laptop) []value {
+		return texts(laptop.GetKeyboard().GetLayout().String())
+	})
+	addField("keyboard.backlit", kindBool, nil, func(laptop *pb.Laptop) []value {
+		return []value{{boolean: laptop.GetKeyboard().GetBacklit()}}
+	})
+}
+
+func addField(name string, kind fieldKind, enumValues map[string]int32, values func(laptop *pb.Laptop) []value) {
+	fields[name] = &field{
+		name:       name,
+		kind:       kind,
+		enumValues: enumValues,
+		values:     values,
+	}
+}
+
+func lookupField(name string) *field {
+	return fields[strings.ToLower(name)]
+}
+
+func numbers(number float64) []value {
+	return []value{{number: number}}
+}
+
+func texts(text string) []value {
+	return []value{{text: text}}
+}
@@ -0,0 +1,136 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// unit is the memory unit written right after a number, e.g. GB in 16GB
+	unit string
+	// column is the 1-based position of the first character of the token
+	column int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text+t.unit)
+	}
+}
+
+// SyntaxError describes why a query cannot be parsed and where
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+func errorAt(column int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", column: column})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", column: column})
+			i++
+		case r == '=':
+			tokens = append(tokens, token{kind: tokenOperator, text: "=", column: column})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, errorAt(column, "unexpected character '!', did you mean '!='")
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, column: column})
+			i += len(op)
+		case r == '"' || r == '\'':
+			end := i + 1
+			var text strings.Builder
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				text.WriteRune(runes[end])
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errorAt(column, "unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), column: column})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			unitStart := end
+			for end < len(runes) && unicode.IsLetter(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, token{
+				kind:   tokenNumber,
+				text:   string(runes[i:unitStart]),
+				unit:   string(runes[unitStart:end]),
+				column: column,
+			})
+			i = end
+		case isIdentStart(r):
+			end := i
+			for end < len(runes) && (isIdentStart(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[i:end]), column: column})
+			i = end
+		default:
+			return nil, errorAt(column, "unexpected character %q", r)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, column: len(runes) + 1})
+	return tokens, nil
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
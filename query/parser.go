@@ -0,0 +1,262 @@
+// Package query parses textual laptop queries such as
+//
+//	brand in ("Dell", "Lenovo") and ram >= 16GB and price < 2000 and screen.panel = OLED
+//
+// into an expression that can be evaluated against a pb.Laptop.
+//
+// A query combines comparisons with and, or, not and parentheses. A comparison is a field,
+// an operator (=, !=, <, <=, >, >= or in) and a literal. Memory fields (ram, gpu.memory, ssd, hdd)
+// take a number with a unit (BIT, B, KB, MB, GB, TB), enum fields (screen.panel, keyboard.layout)
+// take an enum name, boolean fields take true or false and string fields take a quoted string.
+package query
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxNesting is how deep parentheses and not can nest, the parser recurses for each level
+const maxNesting = 100
+
+type parser struct {
+	tokens []token
+	pos    int
+	// depth is the nesting of the expression being parsed
+	depth int
+}
+
+// Parse parses the query, the returned error is a *SyntaxError
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, errorAt(next.column, "unexpected %v, expected and/or", next)
+	}
+	return expr, nil
+}
+
+// Fields returns the names of the fields a query can use
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	t := p.peek()
+	if p.acceptKeyword("not") {
+		err := p.enter(t)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		p.depth--
+		return &notExpr{expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLeftParen:
+		err := p.enter(t)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing.kind != tokenRightParen {
+			return nil, errorAt(closing.column, "unexpected %v, expected ')'", closing)
+		}
+		p.depth--
+		return expr, nil
+	case tokenIdent:
+		return p.parseComparison(t)
+	default:
+		return nil, errorAt(t.column, "unexpected %v, expected a field name", t)
+	}
+}
+
+// enter counts the nesting opened by the token, a deeper query could overflow the stack
+func (p *parser) enter(t token) error {
+	if p.depth >= maxNesting {
+		return errorAt(t.column, "query nests more than %d levels", maxNesting)
+	}
+	p.depth++
+	return nil
+}
+
+func (p *parser) parseComparison(name token) (Expr, error) {
+	f := lookupField(name.text)
+	if f == nil {
+		return nil, errorAt(name.column, "unknown field %q, known fields are %s", name.text, strings.Join(Fields(), ", "))
+	}
+
+	if p.acceptKeyword("in") {
+		return p.parseIn(f)
+	}
+
+	operator := p.next()
+	if operator.kind != tokenOperator {
+		return nil, errorAt(operator.column, "unexpected %v, expected a comparison operator", operator)
+	}
+	if (f.kind == kindString || f.kind == kindEnum || f.kind == kindBool) && operator.text != "=" && operator.text != "!=" {
+		return nil, errorAt(operator.column, "operator %s cannot be used with field %s", operator.text, f.name)
+	}
+
+	literal, err := p.parseLiteral(f)
+	if err != nil {
+		return nil, err
+	}
+	return &comparisonExpr{field: f, operator: operator.text, literals: []value{literal}}, nil
+}
+
+func (p *parser) parseIn(f *field) (Expr, error) {
+	if f.kind == kindBool {
+		return nil, errorAt(p.tokens[p.pos-1].column, "operator in cannot be used with field %s", f.name)
+	}
+	open := p.next()
+	if open.kind != tokenLeftParen {
+		return nil, errorAt(open.column, "unexpected %v, expected '('", open)
+	}
+
+	var literals []value
+	for {
+		literal, err := p.parseLiteral(f)
+		if err != nil {
+			return nil, err
+		}
+		literals = append(literals, literal)
+
+		t := p.next()
+		if t.kind == tokenRightParen {
+			break
+		}
+		if t.kind != tokenComma {
+			return nil, errorAt(t.column, "unexpected %v, expected ',' or ')'", t)
+		}
+	}
+	return &comparisonExpr{field: f, operator: "in", literals: literals}, nil
+}
+
+func (p *parser) parseLiteral(f *field) (value, error) {
+	t := p.next()
+	switch f.kind {
+	case kindNumber, kindMemory:
+		if t.kind != tokenNumber {
+			return value{}, errorAt(t.column, "unexpected %v, field %s expects a number", t, f.name)
+		}
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return value{}, errorAt(t.column, "invalid number %q", t.text)
+		}
+		if f.kind == kindNumber {
+			if t.unit != "" {
+				return value{}, errorAt(t.column, "field %s doesn't take a unit", f.name)
+			}
+			return value{number: number}, nil
+		}
+		bits, ok := memoryUnits[strings.ToUpper(t.unit)]
+		if !ok {
+			return value{}, errorAt(t.column, "field %s expects a memory unit such as GB, got %q", f.name, t.unit)
+		}
+		return value{number: number * bits}, nil
+	case kindString:
+		if t.kind != tokenString {
+			return value{}, errorAt(t.column, "unexpected %v, field %s expects a quoted string", t, f.name)
+		}
+		return value{text: t.text}, nil
+	case kindBool:
+		if t.kind == tokenIdent && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")) {
+			return value{boolean: strings.EqualFold(t.text, "true")}, nil
+		}
+		return value{}, errorAt(t.column, "unexpected %v, field %s expects true or false", t, f.name)
+	default:
+		if t.kind != tokenIdent && t.kind != tokenString {
+			return value{}, errorAt(t.column, "unexpected %v, field %s expects one of %s", t, f.name, enumNames(f))
+		}
+		name := strings.ToUpper(t.text)
+		if _, ok := f.enumValues[name]; !ok {
+			return value{}, errorAt(t.column, "unknown value %q for field %s, expected one of %s", t.text, f.name, enumNames(f))
+		}
+		return value{text: name}, nil
+	}
+}
+
+func enumNames(f *field) string {
+	names := make([]string, 0, len(f.enumValues))
+	for name := range f.enumValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,112 @@
+package query_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"grpc-go/pb"
+	"grpc-go/query"
+	"grpc-go/sample"
+	"strings"
+	"testing"
+)
+
+func newQueryTestLaptop() *pb.Laptop {
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Dell"
+	laptop.Name = "XPS"
+	laptop.PriceUsd = 1800
+	laptop.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+	laptop.Cpu.NumberCores = 8
+	laptop.Gpus = []*pb.GPU{
+		{Brand: "Nvidia", Memory: &pb.Memory{Value: 6, Unit: pb.Memory_GIGABYTE}},
+		{Brand: "AMD", Memory: &pb.Memory{Value: 2048, Unit: pb.Memory_MEGABYTE}},
+	}
+	laptop.Screen.Panel = pb.Screen_OLED
+	laptop.Screen.Multitouch = true
+	laptop.Keyboard.Layout = pb.Keyboard_QWERTZ
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4.4}
+	return laptop
+}
+
+func TestParse_Eval(t *testing.T) {
+	t.Parallel()
+
+	testCase := []struct {
+		query   string
+		matched bool
+	}{
+		{query: `brand in ("Dell","Lenovo") and ram >= 16GB and price < 2000 and screen.panel = OLED`, matched: true},
+		{query: `brand = "dell"`, matched: true},
+		{query: `brand != 'Dell'`, matched: false},
+		{query: `ram > 16GB`, matched: false},
+		{query: `ram = 16384MB`, matched: true},
+		{query: `price <= 1800 and price >= 1800`, matched: true},
+		{query: `cpu.cores in (4, 8)`, matched: true},
+		{query: `gpu.brand = "AMD" and gpu.memory >= 6GB`, matched: true},
+		{query: `gpu.memory < 4gb`, matched: true},
+		{query: `screen.multitouch = true and keyboard.layout = qwertz`, matched: true},
+		{query: `keyboard.layout in (QWERTY, AZERTY)`, matched: false},
+		{query: `weight < 2.1 and weight > 1.9`, matched: true},
+		{query: `not brand = "Dell" or price < 1000`, matched: false},
+		{query: `not (brand = "Dell" and price > 2000)`, matched: true},
+		{query: `brand = "Apple" or (name = "XPS" and screen.panel != IPS)`, matched: true},
+	}
+
+	laptop := newQueryTestLaptop()
+	for _, tc := range testCase {
+		expr, err := query.Parse(tc.query)
+		require.NoError(t, err, tc.query)
+		require.Equal(t, tc.matched, expr.Eval(laptop), tc.query)
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	t.Parallel()
+
+	testCase := []struct {
+		query  string
+		column int
+	}{
+		{query: `color = "red"`, column: 1},
+		{query: `brand = Dell`, column: 9},
+		{query: `brand < "Dell"`, column: 7},
+		{query: `ram >= 16`, column: 8},
+		{query: `price < 2000GB`, column: 9},
+		{query: `ram >= 16XB`, column: 8},
+		{query: `screen.panel = LCD`, column: 16},
+		{query: `screen.multitouch = yes`, column: 21},
+		{query: `brand in ("Dell" "Lenovo")`, column: 18},
+		{query: `(price < 2000`, column: 14},
+		{query: `price < 2000 price > 1000`, column: 14},
+		{query: `brand = "Dell`, column: 9},
+		{query: `price ! 2000`, column: 7},
+		{query: ``, column: 1},
+		{query: strings.Repeat("(", 101) + "price < 2000" + strings.Repeat(")", 101), column: 101},
+		{query: strings.Repeat("not ", 101) + "price < 2000", column: 401},
+		{query: strings.Repeat("(", 4<<20), column: 101},
+	}
+
+	for _, tc := range testCase {
+		_, err := query.Parse(tc.query)
+		require.Error(t, err, tc.query)
+
+		var syntaxErr *query.SyntaxError
+		require.True(t, errors.As(err, &syntaxErr), tc.query)
+		require.Equal(t, tc.column, syntaxErr.Column, "%s: %v", tc.query, err)
+	}
+}
+
+func TestParse_Nesting(t *testing.T) {
+	t.Parallel()
+
+	laptop := &pb.Laptop{PriceUsd: 1500}
+	for _, q := range []string{
+		strings.Repeat("(", 100) + "price < 2000" + strings.Repeat(")", 100),
+		strings.Repeat("not ", 100) + "price < 2000",
+		strings.Repeat("(not ", 50) + "price < 2000" + strings.Repeat(")", 50),
+	} {
+		expr, err := query.Parse(q)
+		require.NoError(t, err)
+		require.True(t, expr.Eval(laptop))
+	}
+}
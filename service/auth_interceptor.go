@@ -11,12 +11,15 @@ import (
 
 type AuthInterceptor struct {
 	jwtManager      *JWTManager
+	revocationList  RevocationList
 	accessibleRoles map[string][]string
 }
 
-func NewAuthInterceptor(jwtManager *JWTManager, accessibleRoles map[string][]string) *AuthInterceptor {
+// NewAuthInterceptor returns an interceptor that rejects the access tokens in the revocation list
+func NewAuthInterceptor(jwtManager *JWTManager, revocationList RevocationList, accessibleRoles map[string][]string) *AuthInterceptor {
 	return &AuthInterceptor{
 		jwtManager:      jwtManager,
+		revocationList:  revocationList,
 		accessibleRoles: accessibleRoles,
 	}
 }
@@ -24,11 +27,11 @@ func NewAuthInterceptor(jwtManager *JWTManager, accessibleRoles map[string][]str
 func (interceptor *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		log.Println("--> unary interceptor: ", info.FullMethod)
-		err := interceptor.authorize(ctx, info.FullMethod)
+		claims, err := interceptor.authorize(ctx, info.FullMethod)
 		if err != nil {
 			return nil, err
 		}
-		return handler(ctx, req)
+		return handler(contextWithClaims(ctx, claims), req)
 	}
 
 }
@@ -36,39 +39,72 @@ func (interceptor *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 func (interceptor *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		log.Println("--> unary interceptor: ", info.FullMethod)
-		err := interceptor.authorize(ss.Context(), info.FullMethod)
+		claims, err := interceptor.authorize(ss.Context(), info.FullMethod)
 		if err != nil {
 			return err
 		}
-		return handler(srv, ss)
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: contextWithClaims(ss.Context(), claims)})
 	}
 }
 
-func (interceptor *AuthInterceptor) authorize(ctx context.Context, method string) error {
+// authorizedStream passes the claims of the access token to the stream handler
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
+
+type claimsKey struct{}
+
+func contextWithClaims(ctx context.Context, claims *UserClaims) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the access token the request was authorized with.
+// The claims are only set for the RPCs that require a role.
+func ClaimsFromContext(ctx context.Context) (*UserClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*UserClaims)
+	return claims, ok
+}
+
+func (interceptor *AuthInterceptor) authorize(ctx context.Context, method string) (*UserClaims, error) {
 	accessibleRoles, ok := interceptor.accessibleRoles[method]
 	if !ok {
-		return nil
+		return nil, nil
 	}
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
 	}
 
 	values := md["authorization"]
 	if len(values) == 0 {
-		return status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
 	}
 
 	accessToken := values[0]
 	claims, err := interceptor.jwtManager.Verify(accessToken)
 	if err != nil {
-		return status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
+	}
+	revoked, err := interceptor.revocationList.IsRevoked(claims)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot check access token revocation: %v", err)
+	}
+	if revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "access token is revoked")
 	}
 	for _, role := range accessibleRoles {
 		if role == claims.Role {
-			return nil
+			return claims, nil
 		}
 	}
 
-	return status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
+	return nil, status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
 }
@@ -2,21 +2,80 @@ package service
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"grpc-go/pb"
+	"log"
+	"math/big"
+	"time"
+)
+
+const (
+	// passwordResetDuration is how long a password reset code can be used
+	passwordResetDuration = 15 * time.Minute
+	// maxPasswordResetAttempts is the number of wrong codes that lock a password reset until it expires
+	maxPasswordResetAttempts = 5
 )
 
 type AuthServer struct {
-	userStore  UserStore
-	jwtManager *JWTManager
+	userStore            UserStore
+	jwtManager           *JWTManager
+	refreshTokenStore    RefreshTokenStore
+	refreshTokenDuration time.Duration
+	revocationList       RevocationList
+	passwordResetStore   PasswordResetStore
+	notifier             Notifier
 	pb.UnimplementedAuthServiceServer
 }
 
-func NewAuthServer(userStore UserStore, jwtManager *JWTManager) *AuthServer {
+// NewAuthServer returns a server whose refresh tokens expire if they aren't used for refreshTokenDuration.
+// Logged out access tokens are added to the revocation list, password reset codes are written to the log.
+func NewAuthServer(
+	userStore UserStore,
+	jwtManager *JWTManager,
+	refreshTokenStore RefreshTokenStore,
+	refreshTokenDuration time.Duration,
+	revocationList RevocationList,
+) *AuthServer {
+	return NewAuthServerWithPasswordReset(
+		userStore,
+		jwtManager,
+		refreshTokenStore,
+		refreshTokenDuration,
+		revocationList,
+		NewInMemoryPasswordResetStore(),
+		NewLogNotifier(),
+	)
+}
+
+// NewAuthServerWithPasswordReset returns a server that delivers password reset codes with the notifier
+func NewAuthServerWithPasswordReset(
+	userStore UserStore,
+	jwtManager *JWTManager,
+	refreshTokenStore RefreshTokenStore,
+	refreshTokenDuration time.Duration,
+	revocationList RevocationList,
+	passwordResetStore PasswordResetStore,
+	notifier Notifier,
+) *AuthServer {
 	return &AuthServer{
-		userStore:  userStore,
-		jwtManager: jwtManager,
+		userStore:            userStore,
+		jwtManager:           jwtManager,
+		refreshTokenStore:    refreshTokenStore,
+		refreshTokenDuration: refreshTokenDuration,
+		revocationList:       revocationList,
+		passwordResetStore:   passwordResetStore,
+		notifier:             notifier,
 	}
 }
 
@@ -28,12 +87,377 @@ func (s *AuthServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Login
 	if user == nil || !user.IsCorrectPassword(req.GetPassword()) {
 		return nil, status.Errorf(codes.NotFound, "incorrect username/password")
 	}
+	if user.IsDisabled() {
+		return nil, status.Errorf(codes.PermissionDenied, "user %s is disabled", user.Username)
+	}
 	token, err := s.jwtManager.Generate(user)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot generate access token")
 	}
+	// every login starts a new family of refresh tokens
+	refreshToken, err := s.generateRefreshToken(user.Username, uuid.New().String())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate refresh token: %v", err)
+	}
 	res := &pb.LoginResponse{
-		AccessToken: token,
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+	}
+	return res, nil
+}
+
+func (s *AuthServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	stored, err := s.refreshTokenStore.Use(hashToken(req.GetRefreshToken()))
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token is invalid")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find refresh token: %v", err)
+	}
+	if stored.Used {
+		// the token was already rotated, so either the client or an attacker holds a stolen copy
+		log.Printf("refresh token of %s reused, revoke its family %s", stored.Username, stored.FamilyId)
+		err := s.refreshTokenStore.RevokeFamily(stored.FamilyId)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot revoke refresh tokens: %v", err)
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token was already used")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token is expired")
+	}
+
+	user, err := s.userStore.Find(stored.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "user %s doesn't exist", stored.Username)
+	}
+	if user.IsDisabled() {
+		return nil, status.Errorf(codes.Unauthenticated, "user %s is disabled", stored.Username)
+	}
+	token, err := s.jwtManager.Generate(user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate access token")
+	}
+	refreshToken, err := s.generateRefreshToken(user.Username, stored.FamilyId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate refresh token: %v", err)
+	}
+	res := &pb.RefreshTokenResponse{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
 	}
 	return res, nil
 }
+
+func (s *AuthServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "the request isn't made by an authenticated user")
+	}
+	log.Printf("receive a logout request from %s", claims.Username)
+
+	err := s.revocationList.Revoke(claims.Id, time.Unix(claims.ExpiresAt, 0))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot revoke access token: %v", err)
+	}
+
+	if len(req.GetRefreshToken()) > 0 {
+		// the token is only looked up, so the refresh token of another user is left alone
+		stored, err := s.refreshTokenStore.Find(hashToken(req.GetRefreshToken()))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, status.Errorf(codes.Internal, "cannot find refresh token: %v", err)
+		}
+		if err == nil && stored.Username == claims.Username {
+			err = s.refreshTokenStore.RevokeFamily(stored.FamilyId)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "cannot revoke refresh tokens: %v", err)
+			}
+		}
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *AuthServer) RevokeUserTokens(ctx context.Context, req *pb.RevokeUserTokensRequest) (*pb.RevokeUserTokensResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a revoke-user-tokens request for %s", username)
+
+	user, err := s.userStore.Find(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %s doesn't exist", username)
+	}
+
+	err = revokeUserTokens(username, s.jwtManager, s.revocationList, s.refreshTokenStore)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RevokeUserTokensResponse{}, nil
+}
+
+func (s *AuthServer) GetSigningKeys(ctx context.Context, req *pb.GetSigningKeysRequest) (*pb.GetSigningKeysResponse, error) {
+	res := &pb.GetSigningKeysResponse{}
+	for _, key := range s.jwtManager.SigningKeys() {
+		jwk, err := toPbJSONWebKey(key)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot convert signing key: %v", err)
+		}
+		// the secret of an HMAC key is never published
+		if jwk != nil {
+			res.Keys = append(res.Keys, jwk)
+		}
+	}
+	return res, nil
+}
+
+func toPbJSONWebKey(key *SigningKey) (*pb.JSONWebKey, error) {
+	jwk := &pb.JSONWebKey{
+		Kid: key.Id,
+		Use: "sig",
+		Alg: key.Algorithm,
+	}
+	switch publicKey := key.PublicKey().(type) {
+	case nil:
+		return nil, nil
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// the coordinates are padded to the size of the curve
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = publicKey.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return jwk, nil
+}
+
+func (s *AuthServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a register request for %s", username)
+
+	err := ValidateUsername(username)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid username: %v", err)
+	}
+	err = ValidatePassword(req.GetPassword(), username)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "weak password: %v", err)
+	}
+
+	// a registered user is never an admin
+	user, err := NewUser(username, req.GetPassword(), "user")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create user: %v", err)
+	}
+	err = s.userStore.Save(user)
+	if errors.Is(err, ErrAlreadyExist) {
+		return nil, status.Errorf(codes.AlreadyExists, "username %s is taken", username)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot save user: %v", err)
+	}
+	return &pb.RegisterResponse{}, nil
+}
+
+func (s *AuthServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	username, err := authenticatedUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("receive a change-password request from %s", username)
+
+	user, err := s.userStore.Find(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %s doesn't exist", username)
+	}
+	if !user.IsCorrectPassword(req.GetOldPassword()) {
+		return nil, status.Errorf(codes.PermissionDenied, "incorrect password")
+	}
+	if req.GetNewPassword() == req.GetOldPassword() {
+		return nil, status.Errorf(codes.InvalidArgument, "new password is the same as the old one")
+	}
+
+	err = s.setPassword(username, req.GetNewPassword())
+	if err != nil {
+		return nil, err
+	}
+	// the other sessions have to log in again once their access tokens expire
+	err = s.refreshTokenStore.RevokeUser(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot revoke refresh tokens: %v", err)
+	}
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+func (s *AuthServer) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a request-password-reset request for %s", username)
+
+	user, err := s.userStore.Find(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find user: %v", err)
+	}
+	if user == nil || user.IsDisabled() {
+		// the response is the same, so it cannot be used to find out which users exist
+		return &pb.RequestPasswordResetResponse{}, nil
+	}
+
+	code, err := generateResetCode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate reset code: %v", err)
+	}
+	// a new request replaces the previous code
+	err = s.passwordResetStore.Save(&PasswordReset{
+		Username:  username,
+		CodeHash:  hashToken(code),
+		ExpiresAt: time.Now().Add(passwordResetDuration),
+	})
+	if errors.Is(err, ErrTooManyResetAttempts) {
+		// no new code until the reset with too many wrong codes expires
+		return &pb.RequestPasswordResetResponse{}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot save password reset: %v", err)
+	}
+
+	err = s.notifier.Notify(&Notification{
+		Username: username,
+		Subject:  "Password reset",
+		Body:     fmt.Sprintf("Your password reset code is %s, it expires in %v.", code, passwordResetDuration),
+		SentAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot send reset code: %v", err)
+	}
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+func (s *AuthServer) ConfirmPasswordReset(ctx context.Context, req *pb.ConfirmPasswordResetRequest) (*pb.ConfirmPasswordResetResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a confirm-password-reset request for %s", username)
+
+	// a weak password must not use up the code
+	err := ValidatePassword(req.GetNewPassword(), username)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "weak password: %v", err)
+	}
+	err = s.passwordResetStore.Verify(username, hashToken(req.GetCode()))
+	if errors.Is(err, ErrInvalidResetCode) || errors.Is(err, ErrTooManyResetAttempts) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot verify reset code: %v", err)
+	}
+
+	err = s.setPassword(username, req.GetNewPassword())
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", ErrInvalidResetCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// whoever knew the old password may still hold tokens
+	err = revokeUserTokens(username, s.jwtManager, s.revocationList, s.refreshTokenStore)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ConfirmPasswordResetResponse{}, nil
+}
+
+// revokeAccessTokens rejects the access tokens issued to the user so far, they expire within the token duration
+func revokeAccessTokens(username string, jwtManager *JWTManager, revocationList RevocationList) error {
+	now := time.Now()
+	err := revocationList.RevokeUser(username, now, now.Add(jwtManager.tokenDuration))
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot revoke access tokens: %v", err)
+	}
+	return nil
+}
+
+// revokeUserTokens rejects the access tokens issued to the user so far and deletes their refresh tokens
+func revokeUserTokens(username string, jwtManager *JWTManager, revocationList RevocationList, refreshTokenStore RefreshTokenStore) error {
+	err := revokeAccessTokens(username, jwtManager, revocationList)
+	if err != nil {
+		return err
+	}
+	err = refreshTokenStore.RevokeUser(username)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot revoke refresh tokens: %v", err)
+	}
+	return nil
+}
+
+// setPassword validates the password and saves it as the password of the user,
+// the other fields of the user may change meanwhile and are kept
+func (s *AuthServer) setPassword(username string, password string) error {
+	err := ValidatePassword(password, username)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "weak password: %v", err)
+	}
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot set password: %v", err)
+	}
+	_, err = s.userStore.Update(username, func(user *User) error {
+		user.HashedPassword = hashedPassword
+		user.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return status.Errorf(storeErrorCode(err), "cannot update user: %v", err)
+	}
+	return nil
+}
+
+// generateResetCode returns a random code of 6 digits
+func generateResetCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// generateRefreshToken returns a new random refresh token of the family, only its hash is stored
+func (s *AuthServer) generateRefreshToken(username string, familyId string) (string, error) {
+	data := make([]byte, 32)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", fmt.Errorf("cannot read random bytes: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(data)
+
+	err = s.refreshTokenStore.Save(&RefreshToken{
+		Hash:      hashToken(token),
+		FamilyId:  familyId,
+		Username:  username,
+		ExpiresAt: time.Now().Add(s.refreshTokenDuration),
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot save refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// hashToken returns the form a refresh token or a reset code is stored in
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
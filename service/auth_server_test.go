@@ -0,0 +1,425 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"grpc-go/pb"
+	"grpc-go/service"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func newTestAuthServer(t *testing.T, refreshTokenDuration time.Duration) (*service.AuthServer, *service.AuthInterceptor) {
+	return newTestAuthServerWithNotifier(t, refreshTokenDuration, service.NewLogNotifier())
+}
+
+func newTestAuthServerWithNotifier(t *testing.T, refreshTokenDuration time.Duration, notifier service.Notifier) (*service.AuthServer, *service.AuthInterceptor) {
+	userStore := service.NewInMemoryUserStore()
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+	require.NoError(t, userStore.Save(user))
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	revocationList := service.NewInMemoryRevocationList()
+	authServer := service.NewAuthServerWithPasswordReset(
+		userStore,
+		jwtManager,
+		service.NewInMemoryRefreshTokenStore(),
+		refreshTokenDuration,
+		revocationList,
+		service.NewInMemoryPasswordResetStore(),
+		notifier,
+	)
+	authInterceptor := service.NewAuthInterceptor(jwtManager, revocationList, map[string][]string{
+		logoutMethod:    {"admin", "user"},
+		protectedMethod: {"admin", "user"},
+	})
+	return authServer, authInterceptor
+}
+
+const (
+	logoutMethod    = "/grpc.go.AuthService/Logout"
+	protectedMethod = "/grpc.go.LaptopService/CreateLaptop"
+)
+
+// callWithToken runs the handler of the method behind the interceptor as if the request carried the access token
+func callWithToken(interceptor *service.AuthInterceptor, method string, accessToken string, handler grpc.UnaryHandler) error {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", accessToken))
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err := interceptor.Unary()(ctx, nil, info, handler)
+	return err
+}
+
+func okHandler(ctx context.Context, req interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func TestAuthServer_RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	authServer, authInterceptor := newTestAuthServer(t, time.Hour)
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, login.GetRefreshToken())
+
+	refresh := func(token string) (*pb.RefreshTokenResponse, error) {
+		return authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: token})
+	}
+	rotated, err := refresh(login.GetRefreshToken())
+	require.NoError(t, err)
+	require.NotEqual(t, login.GetRefreshToken(), rotated.GetRefreshToken())
+	err = callWithToken(authInterceptor, protectedMethod, rotated.GetAccessToken(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, ok := service.ClaimsFromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, "alice", claims.Username)
+		require.Equal(t, "user", claims.Role)
+		require.NotEmpty(t, claims.Id)
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	latest, err := refresh(rotated.GetRefreshToken())
+	require.NoError(t, err)
+
+	// replaying a rotated token revokes every token of the login, including the latest one
+	_, err = refresh(login.GetRefreshToken())
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = refresh(latest.GetRefreshToken())
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// another login isn't affected
+	other, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	_, err = refresh(other.GetRefreshToken())
+	require.NoError(t, err)
+
+	_, err = refresh("unknown")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthServer_RefreshTokenExpired(t *testing.T) {
+	t.Parallel()
+
+	authServer, _ := newTestAuthServer(t, time.Nanosecond)
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthServer_Logout(t *testing.T) {
+	t.Parallel()
+
+	authServer, authInterceptor := newTestAuthServer(t, time.Hour)
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	other, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	err = callWithToken(authInterceptor, logoutMethod, login.GetAccessToken(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authServer.Logout(ctx, &pb.LogoutRequest{RefreshToken: login.GetRefreshToken()})
+	})
+	require.NoError(t, err)
+
+	err = callWithToken(authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the tokens of another login are still valid
+	err = callWithToken(authInterceptor, protectedMethod, other.GetAccessToken(), okHandler)
+	require.NoError(t, err)
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: other.GetRefreshToken()})
+	require.NoError(t, err)
+
+	// presenting the refresh token of another user neither revokes nor uses it
+	_, err = authServer.Register(ctx, &pb.RegisterRequest{Username: "bob", Password: "correct-h0rse"})
+	require.NoError(t, err)
+	bob, err := authServer.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "correct-h0rse"})
+	require.NoError(t, err)
+	alice, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	err = callWithToken(authInterceptor, logoutMethod, alice.GetAccessToken(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authServer.Logout(ctx, &pb.LogoutRequest{RefreshToken: bob.GetRefreshToken()})
+	})
+	require.NoError(t, err)
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: bob.GetRefreshToken()})
+	require.NoError(t, err)
+
+	_, err = authServer.Logout(ctx, &pb.LogoutRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthServer_RevokeUserTokens(t *testing.T) {
+	t.Parallel()
+
+	authServer, authInterceptor := newTestAuthServer(t, time.Hour)
+	ctx := context.Background()
+
+	login1, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	login2, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	_, err = authServer.RevokeUserTokens(ctx, &pb.RevokeUserTokensRequest{Username: "alice"})
+	require.NoError(t, err)
+
+	for _, login := range []*pb.LoginResponse{login1, login2} {
+		err = callWithToken(authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	_, err = authServer.RevokeUserTokens(ctx, &pb.RevokeUserTokensRequest{Username: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// iat has a precision of a second, the tokens issued in a later second are accepted
+	time.Sleep(time.Second)
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	err = callWithToken(authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.NoError(t, err)
+}
+
+func TestAuthServer_GetSigningKeys(t *testing.T) {
+	t.Parallel()
+
+	userStore := service.NewInMemoryUserStore()
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+	require.NoError(t, userStore.Save(user))
+	keys, err := service.NewSigningKeyRing("RS256", time.Hour)
+	require.NoError(t, err)
+	jwtManager := service.NewJWTManagerWithKeys(keys, time.Minute)
+	authServer := service.NewAuthServer(userStore, jwtManager, service.NewInMemoryRefreshTokenStore(), time.Hour, service.NewInMemoryRevocationList())
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+
+	res, err := authServer.GetSigningKeys(ctx, &pb.GetSigningKeysRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.GetKeys(), 2)
+
+	// the token is verified offline with the published key matching its kid
+	token, err := jwt.Parse(login.GetAccessToken(), func(token *jwt.Token) (interface{}, error) {
+		for _, key := range res.GetKeys() {
+			if key.GetKid() == token.Header["kid"] {
+				require.Equal(t, "RSA", key.GetKty())
+				require.Equal(t, "RS256", key.GetAlg())
+				n, err := base64.RawURLEncoding.DecodeString(key.GetN())
+				require.NoError(t, err)
+				e, err := base64.RawURLEncoding.DecodeString(key.GetE())
+				require.NoError(t, err)
+				return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "alice", token.Claims.(jwt.MapClaims)["username"])
+
+	// the secret of an HMAC key isn't published
+	authServer, _ = newTestAuthServer(t, time.Hour)
+	res, err = authServer.GetSigningKeys(ctx, &pb.GetSigningKeysRequest{})
+	require.NoError(t, err)
+	require.Empty(t, res.GetKeys())
+}
+
+func TestAuthServer_Register(t *testing.T) {
+	t.Parallel()
+
+	authServer, _ := newTestAuthServer(t, time.Hour)
+	ctx := context.Background()
+
+	testCase := []struct {
+		name     string
+		username string
+		password string
+		code     codes.Code
+	}{
+		{name: "success", username: "bob", password: "correct-h0rse", code: codes.OK},
+		{name: "taken_username", username: "alice", password: "b4ttery-staple", code: codes.AlreadyExists},
+		{name: "short_username", username: "bo", password: "passw0rd", code: codes.InvalidArgument},
+		{name: "invalid_username", username: "bob smith", password: "passw0rd", code: codes.InvalidArgument},
+		{name: "short_password", username: "carol", password: "s3cret", code: codes.InvalidArgument},
+		{name: "password_without_digit", username: "carol", password: "password", code: codes.InvalidArgument},
+		{name: "password_with_username", username: "carol", password: "Carol1234", code: codes.InvalidArgument},
+	}
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := authServer.Register(ctx, &pb.RegisterRequest{Username: tc.username, Password: tc.password})
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "correct-h0rse"})
+	require.NoError(t, err)
+	require.NotEmpty(t, login.GetAccessToken())
+}
+
+func TestAuthServer_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	authServer, authInterceptor := newTestAuthServer(t, time.Hour)
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	changePassword := func(oldPassword string, newPassword string) error {
+		return callWithToken(authInterceptor, protectedMethod, login.GetAccessToken(), func(ctx context.Context, req interface{}) (interface{}, error) {
+			return authServer.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: oldPassword, NewPassword: newPassword})
+		})
+	}
+
+	err = changePassword("wrong", "n3w-password")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = changePassword("secret", "weak")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = changePassword("secret", "n3w-password")
+	require.NoError(t, err)
+
+	_, err = authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "n3w-password"})
+	require.NoError(t, err)
+	// the sessions of the old password cannot be refreshed anymore
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = authServer.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "n3w-password", NewPassword: "0ther-password"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthServer_PasswordReset(t *testing.T) {
+	t.Parallel()
+
+	notifyFile := filepath.Join(t.TempDir(), "notifications.json")
+	authServer, authInterceptor := newTestAuthServerWithNotifier(t, time.Hour, service.NewFileNotifier(notifyFile))
+	ctx := context.Background()
+
+	login, err := authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	// an unknown user gets the same response, but nothing is sent
+	_, err = authServer.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: "unknown"})
+	require.NoError(t, err)
+	require.NoFileExists(t, notifyFile)
+
+	_, err = authServer.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: "alice"})
+	require.NoError(t, err)
+	code := lastResetCode(t, notifyFile, "alice")
+
+	confirm := func(code string, password string) error {
+		_, err := authServer.ConfirmPasswordReset(ctx, &pb.ConfirmPasswordResetRequest{Username: "alice", Code: code, NewPassword: password})
+		return err
+	}
+	err = confirm(wrongCode(code), "n3w-password")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = confirm(code, "weak")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = confirm(code, "n3w-password")
+	require.NoError(t, err)
+
+	// the code is used up, and the tokens of the old password are revoked
+	err = confirm(code, "0ther-password")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = callWithToken(authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "n3w-password"})
+	require.NoError(t, err)
+}
+
+func TestAuthServer_PasswordResetAttempts(t *testing.T) {
+	t.Parallel()
+
+	notifyFile := filepath.Join(t.TempDir(), "notifications.json")
+	authServer, _ := newTestAuthServerWithNotifier(t, time.Hour, service.NewFileNotifier(notifyFile))
+	ctx := context.Background()
+
+	_, err := authServer.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: "alice"})
+	require.NoError(t, err)
+	code := lastResetCode(t, notifyFile, "alice")
+
+	confirm := func(code string) error {
+		_, err := authServer.ConfirmPasswordReset(ctx, &pb.ConfirmPasswordResetRequest{Username: "alice", Code: code, NewPassword: "n3w-password"})
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, codes.InvalidArgument, status.Code(confirm(wrongCode(code))))
+	}
+
+	// a new code keeps the wrong codes counted so far
+	_, err = authServer.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: "alice"})
+	require.NoError(t, err)
+	code = lastResetCode(t, notifyFile, "alice")
+	for i := 0; i < 2; i++ {
+		require.Equal(t, codes.InvalidArgument, status.Code(confirm(wrongCode(code))))
+	}
+
+	// too many wrong codes lock the reset, even the right code is rejected afterwards
+	require.Equal(t, codes.InvalidArgument, status.Code(confirm(code)))
+
+	// and no new code is sent until the reset expires
+	sent, err := os.ReadFile(notifyFile)
+	require.NoError(t, err)
+	_, err = authServer.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Username: "alice"})
+	require.NoError(t, err)
+	unsent, err := os.ReadFile(notifyFile)
+	require.NoError(t, err)
+	require.Equal(t, sent, unsent)
+}
+
+// lastResetCode returns the code of the last notification the file notifier wrote for the user
+func lastResetCode(t *testing.T, notifyFile string, username string) string {
+	file, err := os.Open(notifyFile)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var code string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		notification := &service.Notification{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), notification))
+		if notification.Username == username {
+			code = regexp.MustCompile(`\d{6}`).FindString(notification.Body)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.NotEmpty(t, code)
+	return code
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"grpc-go/pb"
+	"strings"
+)
+
+// immutableLaptopFields are managed by the server and cannot be changed by an update
+var immutableLaptopFields = map[string]bool{
+	"id":         true,
+	"version":    true,
+	"updated_at": true,
+}
+
+// applyUpdateMask returns a copy of current with the fields listed in mask copied from update.
+// A field listed in mask but not set in update is cleared. An empty mask replaces every mutable field.
+func applyUpdateMask(current *pb.Laptop, update *pb.Laptop, mask *fieldmaskpb.FieldMask) (*pb.Laptop, error) {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		fields := update.ProtoReflect().Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			name := string(fields.Get(i).Name())
+			if !immutableLaptopFields[name] {
+				paths = append(paths, name)
+			}
+		}
+	} else if !mask.IsValid(update) {
+		return nil, fmt.Errorf("mask %v doesn't match the laptop fields", paths)
+	}
+
+	merged := proto.Clone(current).(*pb.Laptop)
+	for _, path := range paths {
+		fields := strings.Split(path, ".")
+		if immutableLaptopFields[fields[0]] {
+			return nil, fmt.Errorf("field %s cannot be updated", path)
+		}
+		copyField(merged.ProtoReflect(), update.ProtoReflect(), fields)
+	}
+	return merged, nil
+}
+
+// copyField copies the field at the given path from src to dst, the path must be valid for both messages
+func copyField(dst protoreflect.Message, src protoreflect.Message, path []string) {
+	field := dst.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if len(path) > 1 {
+		copyField(dst.Mutable(field).Message(), src.Get(field).Message(), path[1:])
+		return
+	}
+	if src.Has(field) {
+		dst.Set(field, src.Get(field))
+	} else {
+		dst.Clear(field)
+	}
+}
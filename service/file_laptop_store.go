@@ -0,0 +1,469 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"grpc-go/pb"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	walFileName      = "laptop.wal"
+	snapshotFileName = "laptop.snapshot"
+
+	// defaultSnapshotInterval is the number of log records after which the log is compacted into a snapshot
+	defaultSnapshotInterval = 1000
+
+	// recordHeaderSize is the size of a record header: 4 bytes payload length + 4 bytes CRC32 checksum
+	recordHeaderSize = 8
+	// maxRecordSize protects the replay from allocating huge buffers for a corrupted length
+	maxRecordSize = 64 << 20
+)
+
+const (
+	opSave byte = iota + 1
+	opDelete
+)
+
+var (
+	errTornRecord    = errors.New("torn record")
+	errCorruptRecord = errors.New("corrupt record")
+)
+
+// walFile is the part of *os.File the write-ahead log is written with
+type walFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Seek(offset int64, whence int) (int64, error)
+	Close() error
+}
+
+// FileLaptopStore persists laptops in a data directory. Every change is appended to a write-ahead log
+// before it becomes visible, and the log is periodically compacted into a snapshot.
+// All reads are served by an in-memory store that is rebuilt from the snapshot and the log on startup.
+type FileLaptopStore struct {
+	mutex            sync.Mutex
+	dataDir          string
+	memory           *InMemoryLaptopStore
+	wal              walFile
+	walRecords       int
+	snapshotInterval int
+	// walSize is the size of the valid records of the log, a failed append is cut off there
+	walSize int64
+	// walErr is set once a failed append could not be cut off, nothing is appended after it
+	walErr error
+}
+
+// NewFileLaptopStore opens (or creates) a file-backed laptop store in dataDir and replays its content
+func NewFileLaptopStore(dataDir string) (*FileLaptopStore, error) {
+	return NewFileLaptopStoreWithInterval(dataDir, defaultSnapshotInterval)
+}
+
+// NewFileLaptopStoreWithInterval is like NewFileLaptopStore but compacts the log every snapshotInterval records
+func NewFileLaptopStoreWithInterval(dataDir string, snapshotInterval int) (*FileLaptopStore, error) {
+	if snapshotInterval <= 0 {
+		snapshotInterval = defaultSnapshotInterval
+	}
+	err := os.MkdirAll(dataDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create data directory: %w", err)
+	}
+
+	store := &FileLaptopStore{
+		dataDir:          dataDir,
+		memory:           newInMemoryLaptopStore(),
+		snapshotInterval: snapshotInterval,
+	}
+
+	// the snapshot is replaced atomically, so it is never torn
+	_, err = store.replay(store.snapshotPath(), false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load snapshot: %w", err)
+	}
+
+	validSize, err := store.replay(store.walPath(), true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay write-ahead log: %w", err)
+	}
+
+	store.wal, err = os.OpenFile(store.walPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open write-ahead log: %w", err)
+	}
+	// drop a torn final record so that new records are appended after the last valid one
+	err = store.truncateWAL(validSize)
+	if err != nil {
+		store.wal.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileLaptopStore) Save(laptop *pb.Laptop) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	other, err := s.memory.Find(laptop.Id)
+	if err != nil {
+		return err
+	}
+	if other != nil {
+		return ErrAlreadyExist
+	}
+
+	laptop.Version = 1
+	err = s.appendRecord(opSave, laptop)
+	if err != nil {
+		return err
+	}
+	err = s.memory.Save(laptop)
+	if err != nil {
+		return err
+	}
+	s.compactIfNeeded()
+	return nil
+}
+
+func (s *FileLaptopStore) Update(laptop *pb.Laptop, expectedVersion uint64) (*pb.Laptop, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// all writes hold s.mutex, so the laptop cannot change between the check and the put
+	current, err := s.memory.Find(laptop.Id)
+	if err != nil {
+		return nil, err
+	}
+	err = checkVersion(current, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	other := newRevision(current, laptop)
+	err = s.appendRecord(opSave, other)
+	if err != nil {
+		return nil, err
+	}
+	err = s.memory.put(other)
+	if err != nil {
+		return nil, err
+	}
+	s.compactIfNeeded()
+	return other, nil
+}
+
+func (s *FileLaptopStore) Delete(id string, expectedVersion uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, err := s.memory.Find(id)
+	if err != nil {
+		return err
+	}
+	err = checkVersion(current, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	err = s.appendRecord(opDelete, &pb.Laptop{Id: id})
+	if err != nil {
+		return err
+	}
+	s.memory.remove(id)
+	s.compactIfNeeded()
+	return nil
+}
+
+func (s *FileLaptopStore) Find(id string) (*pb.Laptop, error) {
+	return s.memory.Find(id)
+}
+
+func (s *FileLaptopStore) Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error {
+	return s.memory.Search(ctx, filter, found)
+}
+
+func (s *FileLaptopStore) Facets(ctx context.Context, filter *pb.Filter, spec *pb.FacetSpec) (*pb.Facets, error) {
+	return s.memory.Facets(ctx, filter, spec)
+}
+
+// Snapshot compacts the current content of the store into a snapshot file and truncates the log
+func (s *FileLaptopStore) Snapshot() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.snapshot()
+}
+
+// Close flushes and closes the write-ahead log
+func (s *FileLaptopStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.wal.Sync()
+	if err != nil {
+		return err
+	}
+	return s.wal.Close()
+}
+
+func (s *FileLaptopStore) walPath() string {
+	return filepath.Join(s.dataDir, walFileName)
+}
+
+func (s *FileLaptopStore) snapshotPath() string {
+	return filepath.Join(s.dataDir, snapshotFileName)
+}
+
+func (s *FileLaptopStore) appendRecord(op byte, laptop *pb.Laptop) error {
+	if s.walErr != nil {
+		return fmt.Errorf("write-ahead log is damaged: %w", s.walErr)
+	}
+	record, err := encodeRecord(op, laptop)
+	if err != nil {
+		return err
+	}
+	_, err = s.wal.Write(record)
+	if err != nil {
+		err = fmt.Errorf("cannot write record to write-ahead log: %w", err)
+	} else {
+		err = s.wal.Sync()
+		if err != nil {
+			err = fmt.Errorf("cannot sync write-ahead log: %w", err)
+		}
+	}
+	if err != nil {
+		// a partial record followed by the next ones would keep the log from being replayed
+		truncateErr := s.truncateWAL(s.walSize)
+		if truncateErr != nil {
+			s.walErr = truncateErr
+		}
+		return err
+	}
+	s.walSize += int64(len(record))
+	s.walRecords++
+	return nil
+}
+
+// truncateWAL cuts the log off at size and appends the next records there
+func (s *FileLaptopStore) truncateWAL(size int64) error {
+	err := s.wal.Truncate(size)
+	if err != nil {
+		return fmt.Errorf("cannot truncate write-ahead log: %w", err)
+	}
+	_, err = s.wal.Seek(size, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("cannot seek write-ahead log: %w", err)
+	}
+	s.walSize = size
+	return nil
+}
+
+// compactIfNeeded snapshots the store once enough records were appended to the log.
+// The records are already durable in the log, so a failed compaction only delays the next one.
+func (s *FileLaptopStore) compactIfNeeded() {
+	if s.walRecords < s.snapshotInterval {
+		return
+	}
+	err := s.snapshot()
+	if err != nil {
+		log.Printf("cannot snapshot laptop store: %v", err)
+	}
+}
+
+// snapshot writes every laptop into a temporary file, atomically replaces the snapshot with it
+// and only then truncates the log, so a crash at any point leaves a consistent state behind
+func (s *FileLaptopStore) snapshot() error {
+	tmpPath := s.snapshotPath() + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("cannot create snapshot file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer := bufio.NewWriter(file)
+	err = s.memory.forEach(func(laptop *pb.Laptop) error {
+		record, err := encodeRecord(opSave, laptop)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(record)
+		return err
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write snapshot file: %w", err)
+	}
+
+	err = os.Rename(tmpPath, s.snapshotPath())
+	if err != nil {
+		return fmt.Errorf("cannot rename snapshot file: %w", err)
+	}
+	err = syncDir(s.dataDir)
+	if err != nil {
+		return err
+	}
+
+	err = s.truncateWAL(0)
+	if err != nil {
+		return err
+	}
+	s.walRecords = 0
+	return nil
+}
+
+// replay applies every record of the given file to the in-memory store and returns the size
+// of the valid prefix of the file. A missing file is treated as empty. A torn final record is
+// only ignored if tolerateTorn is set, a damaged record followed by more data is always an error.
+func (s *FileLaptopStore) replay(path string, tolerateTorn bool) (int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		op, laptop, size, err := decodeRecord(reader, info.Size()-offset)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if errors.Is(err, errTornRecord) && tolerateTorn {
+			log.Printf("ignore torn record at offset %d of %s", offset, path)
+			return offset, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("cannot read record at offset %d of %s: %w", offset, path, err)
+		}
+
+		switch op {
+		case opSave:
+			// a laptop saved again after compaction replaces the snapshot copy
+			err = s.memory.put(laptop)
+		case opDelete:
+			s.memory.remove(laptop.Id)
+		default:
+			err = fmt.Errorf("unknown record operation %d at offset %d", op, offset)
+		}
+		if err != nil {
+			return 0, err
+		}
+		offset += size
+		if path == s.walPath() {
+			s.walRecords++
+		}
+	}
+}
+
+func encodeRecord(op byte, laptop *pb.Laptop) ([]byte, error) {
+	data, err := proto.Marshal(laptop)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal laptop: %w", err)
+	}
+	payload := make([]byte, 0, len(data)+1)
+	payload = append(payload, op)
+	payload = append(payload, data...)
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	return append(record, payload...), nil
+}
+
+// decodeRecord reads one record, remaining is the number of bytes of the file from the record on.
+// It returns io.EOF at a clean end of file and errTornRecord when the last record was only partially
+// written. A record that fails its checksum or length check while more data follows it is errCorruptRecord.
+func decodeRecord(reader io.Reader, remaining int64) (byte, *pb.Laptop, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	_, err := io.ReadFull(reader, header)
+	if err == io.EOF {
+		return 0, nil, 0, io.EOF
+	}
+	if err == io.ErrUnexpectedEOF {
+		return 0, nil, 0, errTornRecord
+	}
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	size := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	if size == 0 || size > maxRecordSize {
+		// a crash can leave the end of the file zero filled
+		rest, err := io.ReadAll(reader)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		if isZero(header) && isZero(rest) {
+			return 0, nil, 0, errTornRecord
+		}
+		return 0, nil, 0, fmt.Errorf("%w: invalid length %d", errCorruptRecord, size)
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(reader, payload)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, nil, 0, errTornRecord
+	}
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	recordSize := int64(recordHeaderSize) + int64(size)
+	if crc32.ChecksumIEEE(payload) != checksum {
+		if recordSize == remaining {
+			return 0, nil, 0, errTornRecord
+		}
+		return 0, nil, 0, fmt.Errorf("%w: checksum mismatch", errCorruptRecord)
+	}
+
+	laptop := &pb.Laptop{}
+	err = proto.Unmarshal(payload[1:], laptop)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("cannot unmarshal laptop: %w", err)
+	}
+	return payload[0], laptop, recordSize, nil
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("cannot open directory: %w", err)
+	}
+	defer file.Close()
+	// fsync of a directory is not supported everywhere, the rename is still atomic without it
+	_ = file.Sync()
+	return nil
+}
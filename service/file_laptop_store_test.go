@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"grpc-go/pb"
+	"grpc-go/sample"
+	"grpc-go/service"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLaptopStore_SaveFind(t *testing.T) {
+	t.Parallel()
+
+	store, err := service.NewFileLaptopStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	laptop := sample.NewLaptop()
+	err = store.Save(laptop)
+	require.NoError(t, err)
+
+	err = store.Save(laptop)
+	require.ErrorIs(t, err, service.ErrAlreadyExist)
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	requireSameLaptop(t, laptop, other)
+
+	other, err = store.Find(sample.NewLaptop().Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+}
+
+func TestFileLaptopStore_Search(t *testing.T) {
+	t.Parallel()
+
+	store, err := service.NewFileLaptopStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	filter := &pb.Filter{
+		MaxPriceUsd: 2000,
+		MinCpuCores: 4,
+		MinCpuGhz:   2.2,
+		MinRam:      &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE},
+	}
+
+	cheap := sample.NewLaptop()
+	cheap.PriceUsd = 1999
+	cheap.Cpu.NumberCores = 4
+	cheap.Cpu.MinGhz = 2.5
+	cheap.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+	require.NoError(t, store.Save(cheap))
+
+	expensive := sample.NewLaptop()
+	expensive.PriceUsd = 2500
+	require.NoError(t, store.Save(expensive))
+
+	var found []string
+	err = store.Search(context.Background(), filter, func(laptop *pb.Laptop) error {
+		found = append(found, laptop.Id)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{cheap.Id}, found)
+}
+
+func TestFileLaptopStore_Recovery(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	store, err := service.NewFileLaptopStoreWithInterval(dataDir, 3)
+	require.NoError(t, err)
+
+	laptops := make([]*pb.Laptop, 5)
+	for i := range laptops {
+		laptops[i] = sample.NewLaptop()
+		require.NoError(t, store.Save(laptops[i]))
+	}
+	require.NoError(t, store.Close())
+
+	// 3 laptops went into the snapshot, the other 2 are only in the log
+	_, err = os.Stat(filepath.Join(dataDir, "laptop.snapshot"))
+	require.NoError(t, err)
+
+	store, err = service.NewFileLaptopStoreWithInterval(dataDir, 3)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, laptop := range laptops {
+		other, err := store.Find(laptop.Id)
+		require.NoError(t, err)
+		requireSameLaptop(t, laptop, other)
+	}
+}
+
+func TestFileLaptopStore_TornRecord(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	store, err := service.NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+
+	laptop1 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop1))
+	laptop2 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop2))
+	require.NoError(t, store.Close())
+
+	// simulate a crash in the middle of writing the second record
+	walPath := filepath.Join(dataDir, "laptop.wal")
+	info, err := os.Stat(walPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(walPath, info.Size()-5))
+
+	store, err = service.NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+
+	other, err := store.Find(laptop1.Id)
+	require.NoError(t, err)
+	requireSameLaptop(t, laptop1, other)
+
+	other, err = store.Find(laptop2.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	// the torn tail is dropped, so new records stay readable after another restart
+	laptop3 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop3))
+	require.NoError(t, store.Close())
+
+	store, err = service.NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	other, err = store.Find(laptop3.Id)
+	require.NoError(t, err)
+	requireSameLaptop(t, laptop3, other)
+}
+
+func TestFileLaptopStore_CorruptRecord(t *testing.T) {
+	t.Parallel()
+
+	testCase := []struct {
+		name string
+		file string
+		// offset of the flipped byte, negative offsets count from the end of the file
+		offset int64
+		err    bool
+	}{
+		{name: "middle_of_log", file: "laptop.wal", offset: 20, err: true},
+		{name: "last_record_of_log", file: "laptop.wal", offset: -5, err: false},
+		{name: "snapshot", file: "laptop.snapshot", offset: -5, err: true},
+	}
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// the first 3 laptops go into the snapshot, the other 2 stay in the log
+			dataDir := t.TempDir()
+			store, err := service.NewFileLaptopStoreWithInterval(dataDir, 3)
+			require.NoError(t, err)
+			for i := 0; i < 5; i++ {
+				require.NoError(t, store.Save(sample.NewLaptop()))
+			}
+			require.NoError(t, store.Close())
+
+			path := filepath.Join(dataDir, tc.file)
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			offset := tc.offset
+			if offset < 0 {
+				offset += int64(len(data))
+			}
+			data[offset] ^= 0xff
+			require.NoError(t, os.WriteFile(path, data, 0644))
+
+			store, err = service.NewFileLaptopStoreWithInterval(dataDir, 3)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer store.Close()
+		})
+	}
+}
+
+func TestFileLaptopStore_UpdateDelete(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	store, err := service.NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+
+	laptop1 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop1))
+	laptop2 := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop2))
+
+	update := proto.Clone(laptop1).(*pb.Laptop)
+	update.PriceUsd = 999
+	_, err = store.Update(update, 2)
+	require.ErrorIs(t, err, service.ErrVersionMismatch)
+	updated, err := store.Update(update, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), updated.Version)
+
+	require.ErrorIs(t, store.Delete(laptop2.Id, 5), service.ErrVersionMismatch)
+	require.NoError(t, store.Delete(laptop2.Id, 0))
+	require.ErrorIs(t, store.Delete(laptop2.Id, 0), service.ErrNotFound)
+	require.NoError(t, store.Close())
+
+	store, err = service.NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	other, err := store.Find(laptop1.Id)
+	require.NoError(t, err)
+	requireSameLaptop(t, updated, other)
+
+	other, err = store.Find(laptop2.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+}
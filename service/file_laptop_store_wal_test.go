@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"grpc-go/sample"
+	"testing"
+)
+
+// failingWAL writes only half of the next record, or fails the next sync
+type failingWAL struct {
+	walFile
+	failWrite bool
+	failSync  bool
+}
+
+func (wal *failingWAL) Write(data []byte) (int, error) {
+	if wal.failWrite {
+		wal.failWrite = false
+		n, _ := wal.walFile.Write(data[:len(data)/2])
+		return n, errors.New("disk full")
+	}
+	return wal.walFile.Write(data)
+}
+
+func (wal *failingWAL) Sync() error {
+	if wal.failSync {
+		wal.failSync = false
+		return errors.New("sync failed")
+	}
+	return wal.walFile.Sync()
+}
+
+func TestFileLaptopStore_FailedAppend(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	store, err := NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+	wal := &failingWAL{walFile: store.wal}
+	store.wal = wal
+
+	saved := sample.NewLaptop()
+	require.NoError(t, store.Save(saved))
+
+	// the failed records are cut off the log, so the records after them can be replayed
+	wal.failWrite = true
+	torn := sample.NewLaptop()
+	require.Error(t, store.Save(torn))
+	wal.failSync = true
+	unsynced := sample.NewLaptop()
+	require.Error(t, store.Save(unsynced))
+
+	next := sample.NewLaptop()
+	require.NoError(t, store.Save(next))
+	require.NoError(t, store.Close())
+
+	reopened, err := NewFileLaptopStore(dataDir)
+	require.NoError(t, err)
+	defer reopened.Close()
+	for _, laptop := range []struct {
+		id    string
+		found bool
+	}{
+		{id: saved.Id, found: true},
+		{id: torn.Id},
+		{id: unsynced.Id},
+		{id: next.Id, found: true},
+	} {
+		other, err := reopened.Find(laptop.id)
+		require.NoError(t, err)
+		require.Equal(t, laptop.found, other != nil)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// imageHeaderSize is the number of leading bytes needed to detect the format of an image
+const imageHeaderSize = 30
+
+var ErrInvalidImage = errors.New("invalid image")
+
+// imageFormat is an image format accepted by the image store
+type imageFormat struct {
+	extension    string
+	mimeType     string
+	magic        func(header []byte) bool
+	decodeConfig func(reader io.Reader) (image.Config, error)
+}
+
+var imageFormats = []*imageFormat{
+	{
+		extension: "jpg",
+		mimeType:  "image/jpeg",
+		magic: func(header []byte) bool {
+			return bytes.HasPrefix(header, []byte{0xff, 0xd8, 0xff})
+		},
+		decodeConfig: jpeg.DecodeConfig,
+	},
+	{
+		extension: "png",
+		mimeType:  "image/png",
+		magic: func(header []byte) bool {
+			return bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n"))
+		},
+		decodeConfig: png.DecodeConfig,
+	},
+	{
+		extension: "gif",
+		mimeType:  "image/gif",
+		magic: func(header []byte) bool {
+			return bytes.HasPrefix(header, []byte("GIF87a")) || bytes.HasPrefix(header, []byte("GIF89a"))
+		},
+		decodeConfig: gif.DecodeConfig,
+	},
+	{
+		extension: "webp",
+		mimeType:  "image/webp",
+		magic: func(header []byte) bool {
+			return len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP"
+		},
+		decodeConfig: decodeWebPConfig,
+	},
+}
+
+// imageTypeAliases maps the other usual extensions of a format to its canonical one
+var imageTypeAliases = map[string]string{
+	"jpeg": "jpg",
+	"jpe":  "jpg",
+}
+
+// normalizeImageType returns the canonical extension of a client supplied image type.
+// The type may be empty or start with a dot, as returned by filepath.Ext.
+func normalizeImageType(imageType string) (string, error) {
+	imageType = strings.ToLower(strings.TrimPrefix(imageType, "."))
+	if imageType == "" {
+		return "", nil
+	}
+	if alias, ok := imageTypeAliases[imageType]; ok {
+		imageType = alias
+	}
+	for _, format := range imageFormats {
+		if format.extension == imageType {
+			return imageType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unsupported image type %q", ErrInvalidImage, imageType)
+}
+
+// probeImage detects the format of the image from its content and decodes the dimensions from its header
+func probeImage(reader io.Reader) (*imageFormat, image.Config, error) {
+	buffered := bytes.NewBuffer(nil)
+	header := make([]byte, imageHeaderSize)
+	n, err := io.ReadFull(io.TeeReader(reader, buffered), header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, image.Config{}, fmt.Errorf("cannot read image header: %w", err)
+	}
+	header = header[:n]
+
+	format, err := detectImageFormat(header)
+	if err != nil {
+		return nil, image.Config{}, err
+	}
+	config, err := format.decodeConfig(io.MultiReader(buffered, reader))
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("%w: cannot decode %s header: %v", ErrInvalidImage, format.extension, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, image.Config{}, fmt.Errorf("%w: image has no pixels", ErrInvalidImage)
+	}
+	return format, config, nil
+}
+
+// detectImageFormat returns the format whose magic bytes start the header
+func detectImageFormat(header []byte) (*imageFormat, error) {
+	for _, format := range imageFormats {
+		if format.magic(header) {
+			return format, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: content is not a JPEG, PNG, GIF or WebP image", ErrInvalidImage)
+}
+
+// decodeWebPConfig reads the canvas size from the first chunk of a WebP file,
+// see https://developers.google.com/speed/webp/docs/riff_container
+func decodeWebPConfig(reader io.Reader) (image.Config, error) {
+	header := make([]byte, imageHeaderSize)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	config := image.Config{}
+	chunk := header[20:]
+	switch string(header[12:16]) {
+	case "VP8 ":
+		// lossy: 3 bytes frame tag, 3 bytes start code and two 14 bit dimensions
+		if !bytes.Equal(chunk[3:6], []byte{0x9d, 0x01, 0x2a}) {
+			return image.Config{}, errors.New("missing VP8 start code")
+		}
+		config.Width = int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff)
+		config.Height = int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff)
+	case "VP8L":
+		// lossless: 1 byte signature and two 14 bit dimensions minus one
+		if chunk[0] != 0x2f {
+			return image.Config{}, errors.New("missing VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		config.Width = int(bits&0x3fff) + 1
+		config.Height = int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		// extended: 4 bytes flags and two 24 bit dimensions minus one
+		config.Width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		config.Height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+	default:
+		return image.Config{}, fmt.Errorf("unknown WebP chunk %q", header[12:16])
+	}
+	return config, nil
+}
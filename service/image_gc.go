@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-go/pb"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// imageGCGracePeriod protects the temporary files of the uploads and renditions being written
+	imageGCGracePeriod = time.Hour
+	// uploadExpiry is how long an interrupted resumable upload can be continued
+	uploadExpiry = 7 * 24 * time.Hour
+)
+
+// errStopSearch ends a search once a laptop is found
+var errStopSearch = errors.New("stop search")
+
+// ErrEmptyLaptopStore is returned instead of deleting every image, the laptops are likely kept in memory and lost on restart
+var ErrEmptyLaptopStore = errors.New("laptop store is empty but the image store is not")
+
+// ImageCollector is implemented by image stores that can remove the data nobody refers to
+type ImageCollector interface {
+	// CollectGarbage deletes the images of the laptops that don't exist anymore
+	// and removes the files that don't belong to an image
+	CollectGarbage(laptopExists func(laptopId string) (bool, error)) (*ImageGCStats, error)
+}
+
+type ImageGCStats struct {
+	DeletedImages int
+	RemovedFiles  int
+	FreedBytes    int64
+}
+
+// CollectImageGarbage removes the images of the deleted laptops and the files the image store doesn't refer to.
+// It returns ErrEmptyLaptopStore if there are images but no laptop at all.
+func CollectImageGarbage(collector ImageCollector, laptopStore LaptopStore) (*ImageGCStats, error) {
+	empty := true
+	err := laptopStore.Search(context.Background(), &pb.Filter{MaxPriceUsd: math.Inf(1)}, func(laptop *pb.Laptop) error {
+		empty = false
+		return errStopSearch
+	})
+	if err != nil && !errors.Is(err, errStopSearch) {
+		return nil, fmt.Errorf("cannot search laptops: %w", err)
+	}
+
+	return collector.CollectGarbage(func(laptopId string) (bool, error) {
+		if empty {
+			return false, ErrEmptyLaptopStore
+		}
+		laptop, err := laptopStore.Find(laptopId)
+		return laptop != nil, err
+	})
+}
+
+// RunImageGC collects the garbage of the image store every interval until the context is done
+func RunImageGC(ctx context.Context, collector ImageCollector, laptopStore LaptopStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, err := CollectImageGarbage(collector, laptopStore)
+		if err != nil {
+			log.Printf("cannot collect image garbage: %v", err)
+			continue
+		}
+		log.Printf("image gc deleted %d images, removed %d files, freed %d bytes", stats.DeletedImages, stats.RemovedFiles, stats.FreedBytes)
+	}
+}
+
+func (d *DiskImageStore) CollectGarbage(laptopExists func(laptopId string) (bool, error)) (*ImageGCStats, error) {
+	stats := &ImageGCStats{}
+
+	d.mutex.RLock()
+	images := make([]*ImageInfo, 0, len(d.images))
+	for _, image := range d.images {
+		images = append(images, image)
+	}
+	d.mutex.RUnlock()
+
+	// the laptops are looked up without holding the lock of the store
+	exists := make(map[string]bool)
+	for _, image := range images {
+		found, checked := exists[image.LaptopId]
+		if !checked {
+			var err error
+			found, err = laptopExists(image.LaptopId)
+			if err != nil {
+				return nil, fmt.Errorf("cannot find laptop: %w", err)
+			}
+			exists[image.LaptopId] = found
+		}
+		if found {
+			continue
+		}
+
+		freed, err := d.delete(image.Id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		stats.DeletedImages++
+		stats.FreedBytes += freed
+	}
+
+	err := d.removeUnreferencedFiles(stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// removeUnreferencedFiles removes the files of the image folder that are neither part of an image,
+// the index or a resumable upload
+func (d *DiskImageStore) removeUnreferencedFiles(stats *ImageGCStats) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	referenced := map[string]bool{imageIndexFile: true}
+	for _, image := range d.images {
+		referenced[filepath.Base(image.Path)] = true
+		for _, rendition := range image.Renditions {
+			referenced[filepath.Base(rendition.Path)] = true
+		}
+	}
+
+	entries, err := os.ReadDir(d.imageFolder)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read image folder: %w", err)
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || referenced[name] {
+			continue
+		}
+
+		maxAge := imageGCGracePeriod
+		uploadId, resumable := resumableUploadId(name)
+		if resumable {
+			if d.uploads[uploadId] {
+				continue
+			}
+			maxAge = uploadExpiry
+		}
+		fileInfo, err := entry.Info()
+		if err != nil || now.Sub(d.lastModified(fileInfo, uploadId, resumable)) < maxAge {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(d.imageFolder, name))
+		if err != nil {
+			log.Printf("cannot remove %s: %v", name, err)
+			continue
+		}
+		stats.RemovedFiles++
+		stats.FreedBytes += fileInfo.Size()
+	}
+	return nil
+}
+
+// lastModified returns when the file was written, for a resumable upload it is the last write to any of its files
+func (d *DiskImageStore) lastModified(fileInfo os.FileInfo, uploadId string, resumable bool) time.Time {
+	modified := fileInfo.ModTime()
+	if !resumable {
+		return modified
+	}
+	for _, path := range []string{d.partPath(uploadId), d.statusPath(uploadId)} {
+		other, err := os.Stat(path)
+		if err == nil && other.ModTime().After(modified) {
+			modified = other.ModTime()
+		}
+	}
+	return modified
+}
+
+// resumableUploadId returns the id of the resumable upload the file belongs to
+func resumableUploadId(name string) (string, bool) {
+	if !strings.HasPrefix(name, ".upload-") {
+		return "", false
+	}
+	for _, suffix := range []string{".part", ".json", ".json.tmp"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(strings.TrimPrefix(name, ".upload-"), suffix), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const imageIndexFile = "index.json"
+
+// imageIndex is the persisted form of the images of a DiskImageStore
+type imageIndex struct {
+	Images []*ImageInfo `json:"images"`
+}
+
+func (d *DiskImageStore) indexPath() string {
+	return filepath.Join(d.imageFolder, imageIndexFile)
+}
+
+// saveIndex replaces the index file with the current images, so a crash leaves either the old or the new index
+func (d *DiskImageStore) saveIndex() error {
+	index := imageIndex{Images: make([]*ImageInfo, 0, len(d.images))}
+	for _, image := range d.images {
+		index.Images = append(index.Images, image)
+	}
+	sort.Slice(index.Images, func(i, j int) bool {
+		return index.Images[i].Id < index.Images[j].Id
+	})
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("cannot encode image index: %w", err)
+	}
+
+	tmpPath := d.indexPath() + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("cannot create image index: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write image index: %w", err)
+	}
+
+	err = os.Rename(tmpPath, d.indexPath())
+	if err != nil {
+		return fmt.Errorf("cannot rename image index: %w", err)
+	}
+	return syncDir(d.imageFolder)
+}
+
+// loadIndex reads the index and checks it against the files in the image folder.
+// Images whose file is missing or has another size are dropped and missing renditions are generated again.
+func (d *DiskImageStore) loadIndex() error {
+	data, err := os.ReadFile(d.indexPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read image index: %w", err)
+	}
+	index := imageIndex{}
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return fmt.Errorf("cannot decode image index: %w", err)
+	}
+
+	changed := false
+	for _, image := range index.Images {
+		blob, regenerated, err := d.loadBlob(image)
+		if err != nil {
+			log.Printf("drop image %s of laptop %s: %v", image.Id, image.LaptopId, err)
+			changed = true
+			continue
+		}
+		changed = changed || regenerated
+		image.Path = d.blobPath(image.Digest, image.Type)
+		image.Renditions = blob.renditions
+		d.images[image.Id] = image
+		blob.refs++
+	}
+
+	log.Printf("loaded %d images from %s", len(d.images), d.imageFolder)
+	if changed {
+		return d.saveIndex()
+	}
+	return nil
+}
+
+// loadBlob returns the blob of the image, verifying its file and renditions the first time it is loaded.
+// It reports whether the renditions had to be generated again.
+func (d *DiskImageStore) loadBlob(info *ImageInfo) (*imageBlob, bool, error) {
+	blob := d.blobs[info.Digest]
+	if blob != nil {
+		return blob, false, nil
+	}
+
+	format := imageFormatByExtension(info.Type)
+	if format == nil {
+		return nil, false, fmt.Errorf("unknown image type %s", info.Type)
+	}
+	path := d.blobPath(info.Digest, info.Type)
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot stat image file: %w", err)
+	}
+	if fileInfo.Size() != info.Size {
+		return nil, false, fmt.Errorf("image file has %d bytes instead of %d", fileInfo.Size(), info.Size)
+	}
+
+	blob = &imageBlob{}
+	complete := true
+	for _, rendition := range info.Renditions {
+		renditionFormat := imageFormatByMimeType(rendition.MimeType)
+		if renditionFormat == nil {
+			complete = false
+			break
+		}
+		rendition.Path = d.renditionPath(info.Digest, rendition.Width, rendition.Height, renditionFormat.extension)
+		fileInfo, err := os.Stat(rendition.Path)
+		if err != nil || fileInfo.Size() != rendition.Size {
+			complete = false
+			break
+		}
+		blob.renditions = append(blob.renditions, rendition)
+	}
+	if !complete {
+		log.Printf("generate the renditions of image %s again", info.Id)
+		config := image.Config{Width: info.Width, Height: info.Height}
+		blob.renditions, err = generateRenditions(path, format, config, d.renditionSizes, func(width int, height int, extension string) string {
+			return d.renditionPath(info.Digest, width, height, extension)
+		})
+		if err != nil {
+			// the original is still served without renditions
+			log.Printf("cannot generate the renditions of image %s: %v", info.Id, err)
+			blob.renditions = nil
+		}
+	}
+
+	d.blobs[info.Digest] = blob
+	return blob, !complete, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// maxRenditionPixels limits the size of the images decoded to generate renditions,
+	// a decoded image takes 4 bytes per pixel
+	maxRenditionPixels = 16 << 20
+	// maxConcurrentRenditions limits the number of images decoded at the same time
+	maxConcurrentRenditions = 2
+	renditionJPEGQuality    = 85
+)
+
+// renditionSlots bounds the memory taken by the decoded images of concurrent uploads
+var renditionSlots = make(chan struct{}, maxConcurrentRenditions)
+
+// DefaultRenditionSizes are the longest edges in pixels of the renditions generated for every image
+var DefaultRenditionSizes = []int{128, 512, 1024}
+
+// ImageRendition is a downscaled copy of an image
+type ImageRendition struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MimeType string `json:"mime_type"`
+	Path     string `json:"-"`
+	Size     int64  `json:"size"`
+}
+
+// Rendition returns the largest variant of the image whose width and height don't exceed maxSize,
+// or the smallest rendition if none fits. It returns nil for the original image.
+func (info *ImageInfo) Rendition(maxSize int) *ImageRendition {
+	if maxSize <= 0 || len(info.Renditions) == 0 || (info.Width <= maxSize && info.Height <= maxSize) {
+		return nil
+	}
+	picked := &info.Renditions[0]
+	for i := range info.Renditions {
+		rendition := &info.Renditions[i]
+		if rendition.Width <= maxSize && rendition.Height <= maxSize {
+			picked = rendition
+		}
+	}
+	return picked
+}
+
+// renditionFormat returns the format renditions of an image are encoded in,
+// or nil if the standard library cannot decode the image
+func renditionFormat(format *imageFormat) *imageFormat {
+	switch format.extension {
+	case "jpg", "png":
+		return format
+	case "gif":
+		// the renditions of an animation only show its first frame
+		return imageFormatByExtension("png")
+	}
+	return nil
+}
+
+func imageFormatByExtension(extension string) *imageFormat {
+	for _, format := range imageFormats {
+		if format.extension == extension {
+			return format
+		}
+	}
+	return nil
+}
+
+func imageFormatByMimeType(mimeType string) *imageFormat {
+	for _, format := range imageFormats {
+		if format.mimeType == mimeType {
+			return format
+		}
+	}
+	return nil
+}
+
+// fitSize scales width and height down so that the longest edge is edge pixels, keeping the aspect ratio
+func fitSize(width int, height int, edge int) (int, int) {
+	if width >= height {
+		return edge, maxInt(1, (height*edge+width/2)/width)
+	}
+	return maxInt(1, (width*edge+height/2)/height), edge
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// generateRenditions writes a rendition of the image for each size smaller than the image.
+// pathFor returns the path of a rendition, existing files at that path are replaced.
+func generateRenditions(
+	sourcePath string,
+	format *imageFormat,
+	config image.Config,
+	sizes []int,
+	pathFor func(width int, height int, extension string) string,
+) ([]ImageRendition, error) {
+	target := renditionFormat(format)
+	if target == nil || config.Width*config.Height > maxRenditionPixels {
+		return nil, nil
+	}
+
+	var renditions []ImageRendition
+	var source *image.RGBA
+	for _, size := range sizes {
+		if size >= config.Width && size >= config.Height {
+			continue
+		}
+		if source == nil {
+			renditionSlots <- struct{}{}
+			defer func() { <-renditionSlots }()
+
+			var err error
+			source, err = decodeImageFile(sourcePath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		width, height := fitSize(config.Width, config.Height, size)
+		path := pathFor(width, height, target.extension)
+		written, err := writeRendition(path, target, resizeImage(source, width, height))
+		if err != nil {
+			removeRenditions(renditions)
+			return nil, err
+		}
+		renditions = append(renditions, ImageRendition{
+			Width:    width,
+			Height:   height,
+			MimeType: target.mimeType,
+			Path:     path,
+			Size:     written,
+		})
+	}
+	return renditions, nil
+}
+
+func removeRenditions(renditions []ImageRendition) {
+	for _, rendition := range renditions {
+		os.Remove(rendition.Path)
+	}
+}
+
+func decodeImageFile(path string) (*image.RGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image file: %w", err)
+	}
+	defer file.Close()
+
+	decoded, _, err := image.Decode(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode image: %v", ErrInvalidImage, err)
+	}
+	bounds := decoded.Bounds()
+	source := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(source, source.Bounds(), decoded, bounds.Min, draw.Src)
+	return source, nil
+}
+
+// resizeImage downscales the image by averaging the pixels that fall into each pixel of the result
+func resizeImage(source *image.RGBA, width int, height int) *image.RGBA {
+	sourceWidth, sourceHeight := source.Rect.Dx(), source.Rect.Dy()
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * sourceHeight / height
+		y1 := maxInt(y0+1, (y+1)*sourceHeight/height)
+		for x := 0; x < width; x++ {
+			x0 := x * sourceWidth / width
+			x1 := maxInt(x0+1, (x+1)*sourceWidth/width)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := source.Pix[sy*source.Stride+x0*4 : sy*source.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			count := (x1 - x0) * (y1 - y0)
+			offset := y*result.Stride + x*4
+			for i := range sum {
+				result.Pix[offset+i] = uint8((sum[i] + count/2) / count)
+			}
+		}
+	}
+	return result
+}
+
+// writeRendition encodes the image into a temporary file and renames it to path
+func writeRendition(path string, format *imageFormat, img image.Image) (int64, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), ".rendition-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("cannot create rendition file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	switch format.extension {
+	case "jpg":
+		err = jpeg.Encode(writer, img, &jpeg.Options{Quality: renditionJPEGQuality})
+	default:
+		err = png.Encode(writer, img)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot write rendition file: %w", err)
+	}
+
+	fileInfo, err := os.Stat(file.Name())
+	if err != nil {
+		return 0, fmt.Errorf("cannot stat rendition file: %w", err)
+	}
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return 0, fmt.Errorf("cannot rename rendition file: %w", err)
+	}
+	return fileInfo.Size(), nil
+}
@@ -1,61 +1,595 @@
 package service
 
 import (
-	"bytes"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"hash"
+	"image"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 )
 
+// uploadCheckpointSize is the amount of data a resumable upload receives between two checkpoints
+const uploadCheckpointSize = 4 << 20
+
+var (
+	ErrUploadInProgress = errors.New("upload is already in progress")
+	ErrUploadMismatch   = errors.New("upload belongs to another image")
+	ErrUploadOffset     = errors.New("upload continues at another offset")
+	errImageWriterDone  = errors.New("image writer is already committed or aborted")
+)
+
 type ImageStore interface {
-	Save(laptopId string, imageType string, imageData bytes.Buffer) (string, error)
+	// Create starts a new image of the laptop. The data is written to a temporary file
+	// and the image only becomes visible once the writer is committed.
+	// The image type is a canonical extension, or empty to accept any supported format.
+	Create(laptopId string, imageType string) (ImageWriter, error)
+	// Resume continues the upload with the given id at offset, or starts it at offset 0 if it doesn't exist yet.
+	// It returns ErrUploadOffset without changing anything if the upload continues at another offset.
+	Resume(uploadId string, laptopId string, imageType string, offset int64) (ImageWriter, error)
+	// UploadStatus returns the upload with the given id, or nil if it doesn't exist
+	UploadStatus(uploadId string) (*UploadStatus, error)
+	// Find returns the image with the given id, or nil if it doesn't exist
+	Find(imageId string) (*ImageInfo, error)
+	// List returns the images of the laptop ordered by id
+	List(laptopId string) ([]*ImageInfo, error)
+	// Delete removes the image, its data is deleted once no laptop refers to it anymore
+	Delete(imageId string) error
+}
+
+// ImageWriter receives the data of an image while it is being uploaded
+type ImageWriter interface {
+	io.Writer
+	// Commit checks that the data is an image of the expected type and stores it.
+	// If the laptop already has an image with the same content, that image is returned instead.
+	Commit() (*ImageInfo, error)
+	// Abort discards the data written so far. It does nothing after Commit.
+	Abort() error
+	// Suspend keeps the data written so far if the upload can be resumed, otherwise it aborts.
+	// It does nothing after Commit or Abort.
+	Suspend() error
 }
 
 type ImageInfo struct {
-	LaptopId string
-	Type     string
-	Path     string
+	Id       string `json:"id"`
+	LaptopId string `json:"laptop_id"`
+	// Type is the canonical extension of the image format detected from the content
+	Type     string `json:"type"`
+	MimeType string `json:"mime_type"`
+	// Digest is the hex encoded SHA-256 of the image, images with the same content share a file
+	Digest string `json:"digest"`
+	// Path is derived from the digest and the type, so it isn't persisted
+	Path   string `json:"-"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Renditions are the downscaled copies of the image ordered by size
+	Renditions []ImageRendition `json:"renditions,omitempty"`
 }
 
+func (info *ImageInfo) clone() *ImageInfo {
+	other := *info
+	other.Renditions = append([]ImageRendition(nil), info.Renditions...)
+	return &other
+}
+
+// UploadStatus describes a resumable upload that isn't committed yet
+type UploadStatus struct {
+	UploadId string `json:"upload_id"`
+	LaptopId string `json:"laptop_id"`
+	Type     string `json:"image_type"`
+	// Offset is the number of bytes that are durable on disk
+	Offset int64 `json:"offset"`
+}
+
+// DiskImageStore stores the content of the images in files named after their SHA-256 digest,
+// so an image uploaded for several laptops is only stored once
 type DiskImageStore struct {
-	mutex       sync.RWMutex
-	imageFolder string
-	images      map[string]*ImageInfo
+	mutex          sync.RWMutex
+	imageFolder    string
+	renditionSizes []int
+	images         map[string]*ImageInfo
+	blobs          map[string]*imageBlob
+	// uploads holds the ids of the resumable uploads that have an open writer
+	uploads map[string]bool
+}
+
+// imageBlob is the content shared by the images with the same digest
+type imageBlob struct {
+	refs       int
+	renditions []ImageRendition
+}
+
+// NewDiskImageStore opens the image store in the folder and loads its index
+func NewDiskImageStore(imageFolder string) (*DiskImageStore, error) {
+	return NewDiskImageStoreWithRenditions(imageFolder, DefaultRenditionSizes)
+}
+
+// NewDiskImageStoreWithRenditions returns a store that generates a rendition of every image for each of the given sizes.
+// A size is the longest edge of the rendition in pixels, repeated sizes generate one rendition.
+func NewDiskImageStoreWithRenditions(imageFolder string, renditionSizes []int) (*DiskImageStore, error) {
+	sizes := append([]int(nil), renditionSizes...)
+	sort.Ints(sizes)
+	unique := sizes[:0]
+	for i, size := range sizes {
+		if i == 0 || size != sizes[i-1] {
+			unique = append(unique, size)
+		}
+	}
+	sizes = unique
+	store := &DiskImageStore{
+		imageFolder:    imageFolder,
+		renditionSizes: sizes,
+		images:         make(map[string]*ImageInfo, 0),
+		blobs:          make(map[string]*imageBlob),
+		uploads:        make(map[string]bool),
+	}
+
+	err := store.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (d *DiskImageStore) Create(laptopId string, imageType string) (ImageWriter, error) {
+	file, err := os.CreateTemp(d.imageFolder, ".upload-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create image file: %w", err)
+	}
+	return &diskImageWriter{
+		store:     d,
+		laptopId:  laptopId,
+		imageType: imageType,
+		file:      file,
+		hash:      sha256.New(),
+	}, nil
+}
+
+func (d *DiskImageStore) Resume(uploadId string, laptopId string, imageType string, offset int64) (ImageWriter, error) {
+	d.mutex.Lock()
+	if d.uploads[uploadId] {
+		d.mutex.Unlock()
+		return nil, ErrUploadInProgress
+	}
+	d.uploads[uploadId] = true
+	d.mutex.Unlock()
+
+	writer, err := d.resume(uploadId, laptopId, imageType, offset)
+	if err != nil {
+		d.releaseUpload(uploadId)
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (d *DiskImageStore) resume(uploadId string, laptopId string, imageType string, offset int64) (*diskImageWriter, error) {
+	status, err := d.UploadStatus(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		status = &UploadStatus{
+			UploadId: uploadId,
+			LaptopId: laptopId,
+			Type:     imageType,
+		}
+	} else if status.LaptopId != laptopId || status.Type != imageType {
+		return nil, fmt.Errorf("%w: laptop %s with image type %s", ErrUploadMismatch, status.LaptopId, status.Type)
+	}
+	// nothing is written before the offset is checked, so a wrong offset doesn't start an upload
+	if offset != status.Offset {
+		return nil, fmt.Errorf("%w: offset is %d, not %d", ErrUploadOffset, status.Offset, offset)
+	}
+
+	file, err := os.OpenFile(d.partPath(uploadId), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open upload file: %w", err)
+	}
+	// drop the data received after the last checkpoint, it may not have reached the disk
+	fileInfo, err := file.Stat()
+	if err == nil && fileInfo.Size() < status.Offset {
+		err = fmt.Errorf("upload file is shorter than the committed offset %d", status.Offset)
+	}
+	if err == nil {
+		err = file.Truncate(status.Offset)
+	}
+	hash := sha256.New()
+	if err == nil {
+		_, err = io.Copy(hash, io.NewSectionReader(file, 0, status.Offset))
+	}
+	headerSize := int64(imageHeaderSize)
+	if status.Offset < headerSize {
+		headerSize = status.Offset
+	}
+	header := make([]byte, headerSize)
+	if err == nil {
+		_, err = file.ReadAt(header, 0)
+	}
+	if err == nil {
+		_, err = file.Seek(status.Offset, io.SeekStart)
+	}
+	if err == nil {
+		err = d.writeUploadStatus(status)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot resume upload: %w", err)
+	}
+
+	return &diskImageWriter{
+		store:     d,
+		uploadId:  uploadId,
+		laptopId:  laptopId,
+		imageType: imageType,
+		file:      file,
+		hash:      hash,
+		header:    header,
+		size:      status.Offset,
+		committed: status.Offset,
+	}, nil
+}
+
+func (d *DiskImageStore) UploadStatus(uploadId string) (*UploadStatus, error) {
+	data, err := os.ReadFile(d.statusPath(uploadId))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read upload status: %w", err)
+	}
+
+	status := &UploadStatus{}
+	err = json.Unmarshal(data, status)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode upload status: %w", err)
+	}
+	return status, nil
+}
+
+// writeUploadStatus replaces the status file of the upload, so a crash leaves either the old or the new status
+func (d *DiskImageStore) writeUploadStatus(status *UploadStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("cannot encode upload status: %w", err)
+	}
+	tmpPath := d.statusPath(status.UploadId) + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot write upload status: %w", err)
+	}
+	err = os.Rename(tmpPath, d.statusPath(status.UploadId))
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot rename upload status: %w", err)
+	}
+	return nil
+}
+
+func (d *DiskImageStore) removeUpload(uploadId string) {
+	os.Remove(d.statusPath(uploadId))
+	os.Remove(d.partPath(uploadId))
+}
+
+func (d *DiskImageStore) releaseUpload(uploadId string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.uploads, uploadId)
+}
+
+func (d *DiskImageStore) partPath(uploadId string) string {
+	return filepath.Join(d.imageFolder, ".upload-"+uploadId+".part")
+}
+
+func (d *DiskImageStore) statusPath(uploadId string) string {
+	return filepath.Join(d.imageFolder, ".upload-"+uploadId+".json")
 }
 
-func NewDiskImageStore(imageFolder string) ImageStore {
-	return &DiskImageStore{
-		imageFolder: imageFolder,
-		images:      make(map[string]*ImageInfo, 0),
+// diskImageWriter writes an image to a temporary file in the image folder.
+// The file of a resumable upload is named after the upload and survives a suspend.
+type diskImageWriter struct {
+	store     *DiskImageStore
+	uploadId  string
+	laptopId  string
+	imageType string
+	file      *os.File
+	hash      hash.Hash
+	// header holds the first bytes of the image until the format is checked
+	header    []byte
+	size      int64
+	committed int64
+	done      bool
+}
+
+func (w *diskImageWriter) Write(data []byte) (int, error) {
+	if w.done {
+		return 0, errImageWriterDone
+	}
+	err := w.checkHeader(data)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.file.Write(data)
+	w.hash.Write(data[:n])
+	w.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if w.uploadId != "" && w.size-w.committed >= uploadCheckpointSize {
+		err = w.checkpoint()
+	}
+	return n, err
+}
+
+// checkHeader rejects the content as soon as its header doesn't start like an image of the expected type,
+// so an upload of another file isn't streamed in full before Commit detects it
+func (w *diskImageWriter) checkHeader(data []byte) error {
+	if len(w.header) >= imageHeaderSize {
+		return nil
 	}
+	n := imageHeaderSize - len(w.header)
+	if n > len(data) {
+		n = len(data)
+	}
+	w.header = append(w.header, data[:n]...)
+	if len(w.header) < imageHeaderSize {
+		return nil
+	}
+
+	format, err := detectImageFormat(w.header)
+	if err != nil {
+		return err
+	}
+	if w.imageType != "" && w.imageType != format.extension {
+		return fmt.Errorf("%w: content is %s but the image type is %s", ErrInvalidImage, format.extension, w.imageType)
+	}
+	return nil
 }
 
-func (d *DiskImageStore) Save(laptopId string, imageType string, imageData bytes.Buffer) (string, error) {
+// checkpoint makes the data written so far durable and records its size as the committed offset
+func (w *diskImageWriter) checkpoint() error {
+	err := w.file.Sync()
+	if err != nil {
+		return fmt.Errorf("cannot sync upload file: %w", err)
+	}
+	err = w.store.writeUploadStatus(&UploadStatus{
+		UploadId: w.uploadId,
+		LaptopId: w.laptopId,
+		Type:     w.imageType,
+		Offset:   w.size,
+	})
+	if err != nil {
+		return err
+	}
+	w.committed = w.size
+	return nil
+}
+
+func (w *diskImageWriter) Commit() (*ImageInfo, error) {
+	if w.done {
+		return nil, errImageWriterDone
+	}
+	w.done = true
+	tmpPath := w.file.Name()
+	defer os.Remove(tmpPath)
+	if w.uploadId != "" {
+		defer w.store.releaseUpload(w.uploadId)
+		defer os.Remove(w.store.statusPath(w.uploadId))
+	}
+
+	err := w.file.Sync()
+	closeErr := w.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot write image file: %w", err)
+	}
+
+	format, config, err := probeImageFile(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	if w.imageType != "" && w.imageType != format.extension {
+		return nil, fmt.Errorf("%w: content is %s but the image type is %s", ErrInvalidImage, format.extension, w.imageType)
+	}
+	digest := hex.EncodeToString(w.hash.Sum(nil))
+
+	// renditions are generated before taking the lock, a blob that is already stored has them
+	w.store.mutex.RLock()
+	blob := w.store.blobs[digest]
+	w.store.mutex.RUnlock()
+	var renditions []ImageRendition
+	if blob == nil {
+		renditions, err = generateRenditions(tmpPath, format, config, w.store.renditionSizes, func(width int, height int, extension string) string {
+			return w.store.renditionPath(digest, width, height, extension)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w.store.mutex.Lock()
+	defer w.store.mutex.Unlock()
+
+	for _, image := range w.store.images {
+		if image.LaptopId == w.laptopId && image.Digest == digest {
+			return image.clone(), nil
+		}
+	}
+
 	imageId, err := uuid.NewRandom()
 	if err != nil {
-		return "", fmt.Errorf("cannot generate image id: %w", err)
+		return nil, fmt.Errorf("cannot generate image id: %w", err)
+	}
+	imagePath := w.store.blobPath(digest, format.extension)
+	blob = w.store.blobs[digest]
+	if blob == nil {
+		err = os.Rename(tmpPath, imagePath)
+		if err == nil {
+			err = syncDir(w.store.imageFolder)
+		}
+		if err != nil {
+			removeRenditions(renditions)
+			return nil, fmt.Errorf("cannot rename image file: %w", err)
+		}
+		blob = &imageBlob{renditions: renditions}
+		w.store.blobs[digest] = blob
 	}
 
-	imagePath := fmt.Sprintf("%s/%s.%s", d.imageFolder, imageId, imageType)
-	file, err := os.Create(imagePath)
+	image := &ImageInfo{
+		Id:         imageId.String(),
+		LaptopId:   w.laptopId,
+		Type:       format.extension,
+		MimeType:   format.mimeType,
+		Digest:     digest,
+		Path:       imagePath,
+		Size:       w.size,
+		Width:      config.Width,
+		Height:     config.Height,
+		Renditions: blob.renditions,
+	}
+	w.store.images[image.Id] = image
+	blob.refs++
+
+	err = w.store.saveIndex()
 	if err != nil {
-		return "", fmt.Errorf("cannot create image file: %w", err)
+		w.store.unref(image)
+		return nil, err
 	}
+	return image.clone(), nil
+}
 
-	_, err = imageData.WriteTo(file)
+func (w *diskImageWriter) Abort() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+	w.file.Close()
+	if w.uploadId != "" {
+		w.store.removeUpload(w.uploadId)
+		w.store.releaseUpload(w.uploadId)
+		return nil
+	}
+	err := os.Remove(w.file.Name())
 	if err != nil {
-		return "", fmt.Errorf("cannot write image to file: %w", err)
+		return fmt.Errorf("cannot remove image file: %w", err)
+	}
+	return nil
+}
+
+func (w *diskImageWriter) Suspend() error {
+	if w.uploadId == "" {
+		return w.Abort()
+	}
+	if w.done {
+		return nil
+	}
+	w.done = true
+	defer w.store.releaseUpload(w.uploadId)
+
+	err := w.checkpoint()
+	closeErr := w.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func probeImageFile(path string) (*imageFormat, image.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("cannot open image file: %w", err)
+	}
+	defer file.Close()
+
+	return probeImage(bufio.NewReader(file))
+}
+
+func (d *DiskImageStore) Find(imageId string) (*ImageInfo, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	info := d.images[imageId]
+	if info == nil {
+		return nil, nil
 	}
+	return info.clone(), nil
+}
 
+func (d *DiskImageStore) Delete(imageId string) error {
+	_, err := d.delete(imageId)
+	return err
+}
+
+// delete removes the image and returns the number of bytes freed on disk
+func (d *DiskImageStore) delete(imageId string) (int64, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	d.images[imageId.String()] = &ImageInfo{
-		LaptopId: laptopId,
-		Type:     imageType,
-		Path:     imagePath,
+	image := d.images[imageId]
+	if image == nil {
+		return 0, ErrNotFound
+	}
+	freed, err := d.unref(image)
+	if err != nil {
+		return 0, err
+	}
+	return freed, d.saveIndex()
+}
+
+// unref removes the image from the index and deletes its files if no other image refers to them.
+// It returns the number of bytes freed on disk.
+func (d *DiskImageStore) unref(image *ImageInfo) (int64, error) {
+	delete(d.images, image.Id)
+	blob := d.blobs[image.Digest]
+	blob.refs--
+	if blob.refs > 0 {
+		return 0, nil
 	}
 
-	return imageId.String(), nil
+	delete(d.blobs, image.Digest)
+	removeRenditions(blob.renditions)
+	freed := image.Size
+	for _, rendition := range blob.renditions {
+		freed += rendition.Size
+	}
+	err := os.Remove(image.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("cannot remove image file: %w", err)
+	}
+	return freed, nil
+}
+
+func (d *DiskImageStore) blobPath(digest string, extension string) string {
+	return filepath.Join(d.imageFolder, digest+"."+extension)
+}
+
+func (d *DiskImageStore) renditionPath(digest string, width int, height int, extension string) string {
+	return filepath.Join(d.imageFolder, fmt.Sprintf("%s-%dx%d.%s", digest, width, height, extension))
+}
+
+func (d *DiskImageStore) List(laptopId string) ([]*ImageInfo, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	var images []*ImageInfo
+	for _, info := range d.images {
+		if info.LaptopId == laptopId {
+			images = append(images, info.clone())
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Id < images[j].Id
+	})
+	return images, nil
 }
@@ -0,0 +1,496 @@
+package service_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"grpc-go/sample"
+	"grpc-go/service"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestImage encodes a width x height image in the given format
+func newTestImage(t *testing.T, format string, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	var err error
+	switch format {
+	case "jpg":
+		err = jpeg.Encode(&buffer, img, nil)
+	case "png":
+		err = png.Encode(&buffer, img)
+	case "gif":
+		err = gif.Encode(&buffer, img, nil)
+	case "webp":
+		// the header of a lossless WebP, the store only decodes the dimensions
+		bits := uint32(width-1) | uint32(height-1)<<14
+		buffer.WriteString("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+		err = binary.Write(&buffer, binary.LittleEndian, bits)
+		buffer.Write(make([]byte, 16))
+	}
+	require.NoError(t, err)
+	return buffer.Bytes()
+}
+
+func TestDiskImageStore_Commit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStore(dir)
+	require.NoError(t, err)
+	data := newTestImage(t, "png", 40, 30)
+
+	writer, err := store.Create("laptop", "png")
+	require.NoError(t, err)
+	_, err = writer.Write(data[:10])
+	require.NoError(t, err)
+	_, err = writer.Write(data[10:])
+	require.NoError(t, err)
+
+	// the image isn't visible before it is committed
+	images, err := store.List("laptop")
+	require.NoError(t, err)
+	require.Empty(t, images)
+
+	committed, err := writer.Commit()
+	require.NoError(t, err)
+	require.NoError(t, writer.Abort())
+
+	image, err := store.Find(committed.Id)
+	require.NoError(t, err)
+	require.Equal(t, committed, image)
+	require.Equal(t, int64(len(data)), image.Size)
+	require.Equal(t, "png", image.Type)
+	require.Equal(t, "image/png", image.MimeType)
+	require.Equal(t, 40, image.Width)
+	require.Equal(t, 30, image.Height)
+	stored, err := os.ReadFile(image.Path)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	// the image file and the index
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	digest := sha256.Sum256(data)
+	require.Equal(t, hex.EncodeToString(digest[:]), image.Digest)
+	require.Equal(t, image.Digest+".png", files[0].Name())
+}
+
+func TestDiskImageStore_Abort(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStore(dir)
+	require.NoError(t, err)
+
+	writer, err := store.Create("laptop", "jpg")
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Abort())
+
+	_, err = writer.Commit()
+	require.Error(t, err)
+	images, err := store.List("laptop")
+	require.NoError(t, err)
+	require.Empty(t, images)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestDiskImageStore_Resume(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStore(dir)
+	require.NoError(t, err)
+	uploadId := uuid.New().String()
+	data := newTestImage(t, "jpg", 64, 48)
+
+	// a new upload starts at offset 0, a wrong offset leaves nothing behind
+	_, err = store.Resume(uploadId, "laptop", "jpg", 100)
+	require.ErrorIs(t, err, service.ErrUploadOffset)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	writer, err := store.Resume(uploadId, "laptop", "jpg", 0)
+	require.NoError(t, err)
+	_, err = writer.Write(data[:100])
+	require.NoError(t, err)
+
+	_, err = store.Resume(uploadId, "laptop", "jpg", 100)
+	require.ErrorIs(t, err, service.ErrUploadInProgress)
+	require.NoError(t, writer.Suspend())
+
+	upload, err := store.UploadStatus(uploadId)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), upload.Offset)
+
+	_, err = store.Resume(uploadId, "other", "jpg", 100)
+	require.ErrorIs(t, err, service.ErrUploadMismatch)
+	_, err = store.Resume(uploadId, "laptop", "jpg", 0)
+	require.ErrorIs(t, err, service.ErrUploadOffset)
+
+	writer, err = store.Resume(uploadId, "laptop", "jpg", 100)
+	require.NoError(t, err)
+	_, err = writer.Write(data[100:])
+	require.NoError(t, err)
+	image, err := writer.Commit()
+	require.NoError(t, err)
+	digest := sha256.Sum256(data)
+	require.Equal(t, hex.EncodeToString(digest[:]), image.Digest)
+
+	stored, err := os.ReadFile(image.Path)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	upload, err = store.UploadStatus(uploadId)
+	require.NoError(t, err)
+	require.Nil(t, upload)
+	files, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestDiskImageStore_ImageFormat(t *testing.T) {
+	t.Parallel()
+
+	testCase := []struct {
+		name      string
+		imageType string
+		data      []byte
+		mimeType  string
+		valid     bool
+		// early is set if Write rejects the header, before the rest of the data is streamed
+		early bool
+	}{
+		{name: "jpeg", imageType: "jpg", data: newTestImage(t, "jpg", 17, 9), mimeType: "image/jpeg", valid: true},
+		{name: "png", imageType: "png", data: newTestImage(t, "png", 17, 9), mimeType: "image/png", valid: true},
+		{name: "gif", imageType: "gif", data: newTestImage(t, "gif", 17, 9), mimeType: "image/gif", valid: true},
+		{name: "webp", imageType: "webp", data: newTestImage(t, "webp", 17, 9), mimeType: "image/webp", valid: true},
+		{name: "detected_type", imageType: "", data: newTestImage(t, "png", 17, 9), mimeType: "image/png", valid: true},
+		{name: "type_mismatch", imageType: "jpg", data: newTestImage(t, "png", 17, 9), valid: false, early: true},
+		{name: "not_an_image", imageType: "jpg", data: bytes.Repeat([]byte("hello world "), 10), valid: false, early: true},
+		{name: "short_not_an_image", imageType: "", data: []byte("hello world"), valid: false},
+		{name: "truncated_header", imageType: "png", data: newTestImage(t, "png", 17, 9)[:12], valid: false},
+		{name: "empty", imageType: "", data: nil, valid: false},
+	}
+
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			store, err := service.NewDiskImageStore(dir)
+			require.NoError(t, err)
+			writer, err := store.Create("laptop", tc.imageType)
+			require.NoError(t, err)
+			_, err = writer.Write(tc.data)
+			if tc.early {
+				require.ErrorIs(t, err, service.ErrInvalidImage)
+				require.NoError(t, writer.Abort())
+				files, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Empty(t, files)
+				return
+			}
+			require.NoError(t, err)
+
+			image, err := writer.Commit()
+			if !tc.valid {
+				require.ErrorIs(t, err, service.ErrInvalidImage)
+				files, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Empty(t, files)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.mimeType, image.MimeType)
+			require.Equal(t, 17, image.Width)
+			require.Equal(t, 9, image.Height)
+		})
+	}
+}
+
+func TestDiskImageStore_Deduplicate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStore(dir)
+	require.NoError(t, err)
+	data := newTestImage(t, "png", 20, 20)
+
+	save := func(laptopId string) *service.ImageInfo {
+		writer, err := store.Create(laptopId, "png")
+		require.NoError(t, err)
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+		image, err := writer.Commit()
+		require.NoError(t, err)
+		return image
+	}
+	requireFiles := func(n int) {
+		files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+		require.NoError(t, err)
+		require.Len(t, files, n)
+	}
+
+	image1 := save("laptop1")
+	require.Equal(t, image1.Id, save("laptop1").Id)
+	image2 := save("laptop2")
+	require.NotEqual(t, image1.Id, image2.Id)
+	require.Equal(t, image1.Path, image2.Path)
+	requireFiles(1)
+
+	images, err := store.List("laptop1")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+
+	require.NoError(t, store.Delete(image1.Id))
+	require.ErrorIs(t, store.Delete(image1.Id), service.ErrNotFound)
+	requireFiles(1)
+	stored, err := os.ReadFile(image2.Path)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	require.NoError(t, store.Delete(image2.Id))
+	requireFiles(0)
+
+	// the content can be stored again once its file is gone
+	image3 := save("laptop1")
+	stored, err = os.ReadFile(image3.Path)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+}
+
+func TestDiskImageStore_Renditions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	// a repeated size generates one rendition
+	store, err := service.NewDiskImageStoreWithRenditions(dir, []int{1024, 128, 512, 128})
+	require.NoError(t, err)
+
+	save := func(laptopId string, data []byte) *service.ImageInfo {
+		writer, err := store.Create(laptopId, "")
+		require.NoError(t, err)
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+		image, err := writer.Commit()
+		require.NoError(t, err)
+		return image
+	}
+
+	photo := save("laptop1", newTestImage(t, "jpg", 1200, 600))
+	require.Len(t, photo.Renditions, 3)
+	expected := [][2]int{{128, 64}, {512, 256}, {1024, 512}}
+	for i, rendition := range photo.Renditions {
+		require.Equal(t, expected[i][0], rendition.Width)
+		require.Equal(t, expected[i][1], rendition.Height)
+		require.Equal(t, "image/jpeg", rendition.MimeType)
+
+		file, err := os.Open(rendition.Path)
+		require.NoError(t, err)
+		config, format, err := image.DecodeConfig(file)
+		file.Close()
+		require.NoError(t, err)
+		require.Equal(t, "jpeg", format)
+		require.Equal(t, rendition.Width, config.Width)
+		require.Equal(t, rendition.Height, config.Height)
+		fileInfo, err := os.Stat(rendition.Path)
+		require.NoError(t, err)
+		require.Equal(t, fileInfo.Size(), rendition.Size)
+	}
+
+	require.Nil(t, photo.Rendition(0))
+	require.Nil(t, photo.Rendition(1200))
+	require.Equal(t, 512, photo.Rendition(1000).Width)
+	require.Equal(t, 128, photo.Rendition(64).Width)
+
+	// the renditions are shared with the other images of the same content
+	other := save("laptop2", newTestImage(t, "jpg", 1200, 600))
+	require.Equal(t, photo.Renditions, other.Renditions)
+
+	// small images have no renditions and GIF renditions are PNG
+	require.Empty(t, save("laptop1", newTestImage(t, "png", 100, 50)).Renditions)
+	animation := save("laptop1", newTestImage(t, "gif", 300, 200))
+	require.Len(t, animation.Renditions, 1)
+	require.Equal(t, "image/png", animation.Renditions[0].MimeType)
+	require.Equal(t, 85, animation.Renditions[0].Height)
+
+	require.NoError(t, store.Delete(photo.Id))
+	require.FileExists(t, photo.Renditions[0].Path)
+	require.NoError(t, store.Delete(other.Id))
+	for _, rendition := range photo.Renditions {
+		require.NoFileExists(t, rendition.Path)
+	}
+}
+
+func TestDiskImageStore_Index(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStoreWithRenditions(dir, []int{128})
+	require.NoError(t, err)
+
+	save := func(laptopId string, data []byte) *service.ImageInfo {
+		writer, err := store.Create(laptopId, "")
+		require.NoError(t, err)
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+		image, err := writer.Commit()
+		require.NoError(t, err)
+		return image
+	}
+	photo := save("laptop1", newTestImage(t, "jpg", 300, 200))
+	shared := save("laptop2", newTestImage(t, "jpg", 300, 200))
+	broken := save("laptop1", newTestImage(t, "png", 40, 40))
+
+	// the images are found again after a restart
+	store, err = service.NewDiskImageStoreWithRenditions(dir, []int{128})
+	require.NoError(t, err)
+	found, err := store.Find(photo.Id)
+	require.NoError(t, err)
+	require.Equal(t, photo, found)
+	images, err := store.List("laptop1")
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+
+	// images without file are dropped and missing renditions are generated again
+	require.NoError(t, os.Remove(broken.Path))
+	require.NoError(t, os.Remove(photo.Renditions[0].Path))
+	store, err = service.NewDiskImageStoreWithRenditions(dir, []int{128})
+	require.NoError(t, err)
+	found, err = store.Find(broken.Id)
+	require.NoError(t, err)
+	require.Nil(t, found)
+	found, err = store.Find(shared.Id)
+	require.NoError(t, err)
+	require.Equal(t, shared, found)
+	require.FileExists(t, photo.Renditions[0].Path)
+
+	// the blob stays shared after the reload
+	require.NoError(t, store.Delete(photo.Id))
+	require.FileExists(t, shared.Path)
+	require.NoError(t, store.Delete(shared.Id))
+	require.NoFileExists(t, shared.Path)
+	require.NoFileExists(t, shared.Renditions[0].Path)
+
+	_, err = os.Stat(filepath.Join(dir, "index.json"))
+	require.NoError(t, err)
+}
+
+func TestDiskImageStore_CollectGarbage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := service.NewDiskImageStoreWithRenditions(dir, []int{128})
+	require.NoError(t, err)
+
+	save := func(laptopId string, data []byte) *service.ImageInfo {
+		writer, err := store.Create(laptopId, "")
+		require.NoError(t, err)
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+		image, err := writer.Commit()
+		require.NoError(t, err)
+		return image
+	}
+	kept := save("laptop1", newTestImage(t, "png", 40, 40))
+	orphan := save("deleted", newTestImage(t, "jpg", 300, 200))
+
+	old := time.Now().Add(-48 * time.Hour)
+	stray := filepath.Join(dir, "stray.jpg")
+	require.NoError(t, os.WriteFile(stray, []byte("stray"), 0644))
+	require.NoError(t, os.Chtimes(stray, old, old))
+	recent := filepath.Join(dir, ".upload-123.tmp")
+	require.NoError(t, os.WriteFile(recent, []byte("recent"), 0644))
+
+	// an interrupted upload is kept until it expires
+	writer, err := store.Resume(uuid.New().String(), "laptop1", "jpg", 0)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Suspend())
+	uploads, err := filepath.Glob(filepath.Join(dir, ".upload-*.part"))
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	require.NoError(t, os.Chtimes(uploads[0], old, old))
+
+	stats, err := store.CollectGarbage(func(laptopId string) (bool, error) {
+		return laptopId != "deleted", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.DeletedImages)
+	require.Equal(t, 1, stats.RemovedFiles)
+	require.Equal(t, orphan.Size+orphan.Renditions[0].Size+int64(len("stray")), stats.FreedBytes)
+
+	found, err := store.Find(orphan.Id)
+	require.NoError(t, err)
+	require.Nil(t, found)
+	require.NoFileExists(t, orphan.Path)
+	require.NoFileExists(t, stray)
+	require.FileExists(t, kept.Path)
+	require.FileExists(t, recent)
+	require.FileExists(t, uploads[0])
+
+	// the deletion is persisted in the index
+	store, err = service.NewDiskImageStoreWithRenditions(dir, []int{128})
+	require.NoError(t, err)
+	images, err := store.List("deleted")
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
+
+func TestCollectImageGarbage_EmptyLaptopStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := service.NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	laptopStore := service.NewInMemoryLaptopStore()
+
+	// an empty image store is collected, there is nothing to lose
+	_, err = service.CollectImageGarbage(store, laptopStore)
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	writer, err := store.Create(laptop.Id, "")
+	require.NoError(t, err)
+	_, err = writer.Write(newTestImage(t, "png", 40, 40))
+	require.NoError(t, err)
+	image, err := writer.Commit()
+	require.NoError(t, err)
+
+	// the laptops of a store kept in memory are gone after a restart, their images are kept
+	_, err = service.CollectImageGarbage(store, laptopStore)
+	require.ErrorIs(t, err, service.ErrEmptyLaptopStore)
+	require.FileExists(t, image.Path)
+
+	require.NoError(t, laptopStore.Save(laptop))
+	stats, err := service.CollectImageGarbage(store, laptopStore)
+	require.NoError(t, err)
+	require.Equal(t, 0, stats.DeletedImages)
+	require.FileExists(t, image.Path)
+}
@@ -3,17 +3,24 @@ package service
 import (
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"time"
 )
 
 type JWTManager struct {
-	secretKey     string
+	keys          *SigningKeyRing
 	tokenDuration time.Duration
 }
 
+// NewJWTManager returns a manager signing tokens with HS256, the verifiers need the secret key
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+	return NewJWTManagerWithKeys(newHMACKeyRing(secretKey), tokenDuration)
+}
+
+// NewJWTManagerWithKeys returns a manager signing tokens with the current key of the ring
+func NewJWTManagerWithKeys(keys *SigningKeyRing, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
-		secretKey:     secretKey,
+		keys:          keys,
 		tokenDuration: tokenDuration,
 	}
 }
@@ -25,24 +32,37 @@ type UserClaims struct {
 }
 
 func (manager *JWTManager) Generate(user *User) (string, error) {
+	now := time.Now()
 	claims := UserClaims{
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(manager.tokenDuration).Unix(),
+			// the jti identifies the token in the revocation list
+			Id:        uuid.New().String(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(manager.tokenDuration).Unix(),
 		},
 		Username: user.Username,
 		Role:     user.Role,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	key := manager.keys.Current()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	if key.Id != "" {
+		token.Header["kid"] = key.Id
+	}
+	return token.SignedString(key.signer)
 }
 
 func (manager *JWTManager) Verify(accessToken string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(accessToken, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
+		// tokens without kid were signed by an HMAC key
+		keyId, _ := token.Header["kid"].(string)
+		key, found := manager.keys.Find(keyId)
+		if !found {
+			return nil, fmt.Errorf("unknown signing key %q", keyId)
+		}
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpect token signing method")
 		}
-		return []byte(manager.secretKey), nil
+		return key.verificationKey(), nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -53,3 +73,8 @@ func (manager *JWTManager) Verify(accessToken string) (*UserClaims, error) {
 	}
 	return claims, nil
 }
+
+// SigningKeys returns the keys the tokens are currently verified with
+func (manager *JWTManager) SigningKeys() []*SigningKey {
+	return manager.keys.Keys()
+}
@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"grpc-go/service"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJWTManager(t *testing.T) {
+	t.Parallel()
+
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+
+	testCase := []struct {
+		name      string
+		algorithm string
+	}{
+		{name: "rs256", algorithm: "RS256"},
+		{name: "es256", algorithm: "ES256"},
+		{name: "eddsa", algorithm: "EdDSA"},
+	}
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := service.NewSigningKeyRing(tc.algorithm, time.Hour)
+			require.NoError(t, err)
+			jwtManager := service.NewJWTManagerWithKeys(keys, time.Minute)
+
+			token, err := jwtManager.Generate(user)
+			require.NoError(t, err)
+			requireSignedWith(t, token, tc.algorithm, keys.Current().Id)
+			claims, err := jwtManager.Verify(token)
+			require.NoError(t, err)
+			require.Equal(t, "alice", claims.Username)
+
+			// the rotated key keeps verifying the tokens it signed during the grace period
+			previous := keys.Current()
+			current, err := keys.Rotate()
+			require.NoError(t, err)
+			require.NotEqual(t, previous.Id, current.Id)
+			require.Len(t, jwtManager.SigningKeys(), 2)
+			_, err = jwtManager.Verify(token)
+			require.NoError(t, err)
+
+			rotated, err := jwtManager.Generate(user)
+			require.NoError(t, err)
+			requireSignedWith(t, rotated, tc.algorithm, current.Id)
+			_, err = jwtManager.Verify(rotated)
+			require.NoError(t, err)
+
+			// a token signed by another ring is rejected
+			other, err := service.NewSigningKeyRing(tc.algorithm, time.Hour)
+			require.NoError(t, err)
+			_, err = service.NewJWTManagerWithKeys(other, time.Minute).Verify(token)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestJWTManager_RotationGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+
+	keys, err := service.NewSigningKeyRing("ES256", time.Millisecond)
+	require.NoError(t, err)
+	jwtManager := service.NewJWTManagerWithKeys(keys, time.Minute)
+	token, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = jwtManager.Verify(token)
+	require.Error(t, err)
+	require.Len(t, jwtManager.SigningKeys(), 1)
+}
+
+func TestJWTManager_KeyDir(t *testing.T) {
+	t.Parallel()
+
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	keys, err := service.OpenSigningKeyRing(dir, "EdDSA", time.Hour)
+	require.NoError(t, err)
+	jwtManager := service.NewJWTManagerWithKeys(keys, time.Minute)
+	token, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+	fileInfo, err := os.Stat(filepath.Join(dir, "signing_keys.json"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+
+	// the keys survive a restart and are shared with the servers using the same directory
+	other, err := service.OpenSigningKeyRing(dir, "EdDSA", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, keys.Current().Id, other.Current().Id)
+	otherManager := service.NewJWTManagerWithKeys(other, time.Minute)
+	_, err = otherManager.Verify(token)
+	require.NoError(t, err)
+
+	// a key rotated by one server verifies on the others
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	rotated, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+	_, err = otherManager.Verify(rotated)
+	require.NoError(t, err)
+
+	// another algorithm signs with a new key, the previous keys still verify during the grace period
+	es256, err := service.OpenSigningKeyRing(dir, "ES256", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "ES256", es256.Current().Algorithm)
+	_, err = service.NewJWTManagerWithKeys(es256, time.Minute).Verify(rotated)
+	require.NoError(t, err)
+}
+
+func TestSigningKeyRing_ConcurrentRotate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rings := make([]*service.SigningKeyRing, 8)
+	for i := range rings {
+		ring, err := service.OpenSigningKeyRing(dir, "ES256", time.Hour)
+		require.NoError(t, err)
+		rings[i] = ring
+	}
+
+	// servers rotating at the same time keep each other's new keys
+	keys := make([]*service.SigningKey, len(rings))
+	errs := make([]error, len(rings))
+	var wg sync.WaitGroup
+	for i := range rings {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys[i], errs[i] = rings[i].Rotate()
+		}(i)
+	}
+	wg.Wait()
+
+	restarted, err := service.OpenSigningKeyRing(dir, "ES256", time.Hour)
+	require.NoError(t, err)
+	for i := range rings {
+		require.NoError(t, errs[i])
+		_, ok := restarted.Find(keys[i].Id)
+		require.True(t, ok)
+	}
+}
+
+func TestJWTManager_HMAC(t *testing.T) {
+	t.Parallel()
+
+	user, err := service.NewUser("alice", "secret", "user")
+	require.NoError(t, err)
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	token, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+	requireSignedWith(t, token, "HS256", "")
+	_, err = jwtManager.Verify(token)
+	require.NoError(t, err)
+
+	// an HMAC token isn't accepted in place of an asymmetric one
+	keys, err := service.NewSigningKeyRing("RS256", time.Hour)
+	require.NoError(t, err)
+	_, err = service.NewJWTManagerWithKeys(keys, time.Minute).Verify(token)
+	require.Error(t, err)
+
+	_, err = service.NewSigningKeyRing("HS256", time.Hour)
+	require.Error(t, err)
+}
+
+func requireSignedWith(t *testing.T, token string, algorithm string, keyId string) {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, algorithm, parsed.Header["alg"])
+	if keyId == "" {
+		require.NotContains(t, parsed.Header, "kid")
+	} else {
+		require.Equal(t, keyId, parsed.Header["kid"])
+	}
+}
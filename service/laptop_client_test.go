@@ -2,24 +2,35 @@ package service_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"grpc-go/pb"
 	"grpc-go/sample"
 	"grpc-go/serializer"
 	"grpc-go/service"
 	"io"
+	"math"
 	"net"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestClientCreateLaptop(t *testing.T) {
 	t.Parallel()
 
 	laptopStore := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore("tmp" + string(os.PathSeparator) + "img")
+	imageStore, err := service.NewDiskImageStore("tmp" + string(os.PathSeparator) + "img")
+	require.NoError(t, err)
 	ratingStore := service.NewInMemoryRatingStore()
 	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, ratingStore)
 	laptopClient := newTestLaptopClient(t, serverAddress)
@@ -36,6 +47,8 @@ func TestClientCreateLaptop(t *testing.T) {
 	other, err := laptopStore.Find(res.Id)
 	require.NoError(t, err)
 	require.NotNil(t, other)
+	// the store assigns the first version to a new laptop
+	laptop.Version = 1
 	requireSameLaptop(t, laptop, other)
 }
 
@@ -75,7 +88,8 @@ func TestClientSearchLaptop(t *testing.T) {
 		MinRam:      &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE},
 	}
 	store := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore("tmp" + string(os.PathSeparator) + "img")
+	imageStore, err := service.NewDiskImageStore("tmp" + string(os.PathSeparator) + "img")
+	require.NoError(t, err)
 	ratingStore := service.NewInMemoryRatingStore()
 	expectedIDs := make(map[string]bool)
 
@@ -143,39 +157,657 @@ func TestClientRateLaptop(t *testing.T) {
 	err := laptopStore.Save(laptop)
 	require.NoError(t, err)
 
-	serverAddress := startTestLaptopServer(t, laptopStore, nil, ratingStore)
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	serverAddress := startTestAuthLaptopServer(t, jwtManager, laptopStore, ratingStore)
 	laptopClient := newTestLaptopClient(t, serverAddress)
 
+	rate := func(username string, score float64) *pb.RateLaptopResponse {
+		stream, err := laptopClient.RateLaptop(withTestAccessToken(t, jwtManager, username, "user"))
+		require.NoError(t, err)
+		err = stream.Send(&pb.RateLaptopRequest{LaptopId: laptop.GetId(), Score: score})
+		require.NoError(t, err)
+		err = stream.CloseSend()
+		require.NoError(t, err)
+
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, laptop.GetId(), res.GetLaptopId())
+		_, err = stream.Recv()
+		require.Equal(t, io.EOF, err)
+		return res
+	}
+
+	// a user rating the laptop again replaces the previous score
+	scores := []float64{8, 7.5, 10}
+	for _, score := range scores {
+		res := rate("alice", score)
+		require.Equal(t, uint32(1), res.GetRatedCount())
+		require.Equal(t, score, res.GetAverageScore())
+	}
+	res := rate("bob", 6)
+	require.Equal(t, uint32(2), res.GetRatedCount())
+	require.Equal(t, 8.0, res.GetAverageScore())
+
+	retracted, err := laptopClient.RetractRating(withTestAccessToken(t, jwtManager, "alice", "user"), &pb.RetractRatingRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), retracted.GetRatedCount())
+	require.Equal(t, 6.0, retracted.GetAverageScore())
+	rating, err := ratingStore.Find(laptop.GetId())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), rating.Count)
+
+	_, err = laptopClient.RetractRating(withTestAccessToken(t, jwtManager, "alice", "user"), &pb.RetractRatingRequest{LaptopId: laptop.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
 	stream, err := laptopClient.RateLaptop(context.Background())
 	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
 
-	scores := []float64{8, 7.5, 10}
-	averages := []float64{8, 7.75, 8.5}
+func TestClientRateLaptopInvalidScore(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := service.NewInMemoryRatingStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	serverAddress := startTestAuthLaptopServer(t, jwtManager, laptopStore, ratingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
 
-	n := len(scores)
-	for i := 0; i < n; i++ {
-		req := &pb.RateLaptopRequest{
-			LaptopId: laptop.GetId(),
-			Score:    scores[i],
+	testCase := []struct {
+		name     string
+		laptopId string
+		score    float64
+		valid    bool
+	}{
+		{name: "nan", laptopId: laptop.GetId(), score: math.NaN()},
+		{name: "positive_inf", laptopId: laptop.GetId(), score: math.Inf(1)},
+		{name: "negative_inf", laptopId: laptop.GetId(), score: math.Inf(-1)},
+		{name: "negative", laptopId: laptop.GetId(), score: -5},
+		{name: "below_min", laptopId: laptop.GetId(), score: 0.5},
+		{name: "above_max", laptopId: laptop.GetId(), score: 10.5},
+		{name: "huge", laptopId: laptop.GetId(), score: 1e9},
+		{name: "off_step", laptopId: laptop.GetId(), score: 7.25},
+		{name: "unknown_laptop", laptopId: "unknown", score: 7},
+		{name: "min", laptopId: laptop.GetId(), score: 1, valid: true},
+		{name: "half_step", laptopId: laptop.GetId(), score: 8.5, valid: true},
+		{name: "max", laptopId: laptop.GetId(), score: 10, valid: true},
+	}
+
+	// every request is answered on the same stream, a rejected score doesn't end it
+	stream, err := laptopClient.RateLaptop(withTestAccessToken(t, jwtManager, "alice", "user"))
+	require.NoError(t, err)
+	for _, tc := range testCase {
+		err := stream.Send(&pb.RateLaptopRequest{LaptopId: tc.laptopId, Score: tc.score})
+		require.NoError(t, err)
+	}
+	require.NoError(t, stream.CloseSend())
+
+	for _, tc := range testCase {
+		res, err := stream.Recv()
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.laptopId, res.GetLaptopId(), tc.name)
+		if !tc.valid {
+			require.NotEmpty(t, res.GetError(), tc.name)
+			require.Zero(t, res.GetRatedCount(), tc.name)
+			continue
 		}
+		require.Empty(t, res.GetError(), tc.name)
+		require.Equal(t, uint32(1), res.GetRatedCount(), tc.name)
+		require.Equal(t, tc.score, res.GetAverageScore(), tc.name)
+	}
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+
+	rating, err := ratingStore.Find(laptop.GetId())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), rating.Count)
+	require.Equal(t, 10.0, rating.Sum)
+}
+
+func TestClientLaptopRating(t *testing.T) {
+	t.Parallel()
 
-		err := stream.Send(req)
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := service.NewInMemoryRatingStore()
+	newLaptop := func(price float64, scores ...float64) *pb.Laptop {
+		laptop := sample.NewLaptop()
+		laptop.PriceUsd = price
+		require.NoError(t, laptopStore.Save(laptop))
+		for i, score := range scores {
+			_, err := ratingStore.Add(laptop.GetId(), fmt.Sprintf("user%d", i), score)
+			require.NoError(t, err)
+		}
+		return laptop
+	}
+	many := func(n int, score float64) []float64 {
+		scores := make([]float64, n)
+		for i := range scores {
+			scores[i] = score
+		}
+		return scores
+	}
+
+	perfect := newLaptop(1000, 10)
+	popular := newLaptop(1000, many(20, 9)...)
+	unrated := newLaptop(1000)
+	expensive := newLaptop(3000, many(50, 10)...)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, ratingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	res, err := laptopClient.GetLaptopRating(context.Background(), &pb.GetLaptopRatingRequest{LaptopId: perfect.GetId()})
+	require.NoError(t, err)
+	rating := res.GetRating()
+	require.Equal(t, uint32(1), rating.GetRatedCount())
+	require.Equal(t, 10.0, rating.GetAverageScore())
+	require.InDelta(t, 65.0/11, rating.GetWeightedScore(), 1e-9)
+	require.Equal(t, []uint32{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, rating.GetHistogram())
+
+	res, err = laptopClient.GetLaptopRating(context.Background(), &pb.GetLaptopRatingRequest{LaptopId: unrated.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), res.GetRating().GetRatedCount())
+	require.Equal(t, service.DefaultRatingPrior.Mean, res.GetRating().GetWeightedScore())
+
+	_, err = laptopClient.GetLaptopRating(context.Background(), &pb.GetLaptopRatingRequest{LaptopId: uuid.New().String()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	listTopRated := func(req *pb.ListTopRatedLaptopsRequest) []string {
+		req.Filter = &pb.Filter{MaxPriceUsd: 2000}
+		res, err := laptopClient.ListTopRatedLaptops(context.Background(), req)
 		require.NoError(t, err)
+		var ids []string
+		for _, rated := range res.GetLaptops() {
+			require.Equal(t, rated.GetLaptop().GetId(), rated.GetRating().GetLaptopId())
+			ids = append(ids, rated.GetLaptop().GetId())
+		}
+		return ids
 	}
 
-	err = stream.CloseSend()
+	// many high ratings outrank a single perfect one, the expensive laptop doesn't match the filter
+	require.Equal(t, []string{popular.GetId(), perfect.GetId(), unrated.GetId()}, listTopRated(&pb.ListTopRatedLaptopsRequest{}))
+	require.Equal(t, []string{popular.GetId()}, listTopRated(&pb.ListTopRatedLaptopsRequest{Limit: 1}))
+	require.Equal(t, []string{popular.GetId(), perfect.GetId()}, listTopRated(&pb.ListTopRatedLaptopsRequest{MinRatedCount: 1}))
+	require.Equal(t, []string{popular.GetId()}, listTopRated(&pb.ListTopRatedLaptopsRequest{MinWeightedScore: 7}))
+	require.NotContains(t, listTopRated(&pb.ListTopRatedLaptopsRequest{}), expensive.GetId())
+
+	_, err = laptopClient.ListTopRatedLaptops(context.Background(), &pb.ListTopRatedLaptopsRequest{Limit: 1000})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// startTestAuthLaptopServer starts a laptop server that authorizes the rating RPCs with the access tokens of jwtManager
+func startTestAuthLaptopServer(t *testing.T, jwtManager *service.JWTManager, laptopStore service.LaptopStore, ratingStore service.RateStore) string {
+	accessibleRoles := map[string][]string{
+		"/grpc.go.LaptopService/RateLaptop":    {"admin", "user"},
+		"/grpc.go.LaptopService/RetractRating": {"admin", "user"},
+	}
+	authInterceptor := service.NewAuthInterceptor(jwtManager, service.NewInMemoryRevocationList(), accessibleRoles)
+	laptopServer := service.NewLaptopServer(laptopStore, nil, ratingStore)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		grpc.StreamInterceptor(authInterceptor.Stream()))
+	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	return listener.Addr().String()
+}
+
+// withTestAccessToken returns a context that authenticates the requests as the user with the role
+func withTestAccessToken(t *testing.T, jwtManager *service.JWTManager, username string, role string) context.Context {
+	accessToken, err := jwtManager.Generate(&service.User{Username: username, Role: role})
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", accessToken)
+}
+
+func TestClientGetLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop1 := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop1))
+	laptop2 := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop2))
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	res, err := laptopClient.GetLaptop(context.Background(), &pb.GetLaptopRequest{Id: laptop1.Id})
+	require.NoError(t, err)
+	requireSameLaptop(t, laptop1, res.GetLaptop())
+
+	missingID := sample.NewLaptop().Id
+	_, err = laptopClient.GetLaptop(context.Background(), &pb.GetLaptopRequest{Id: missingID})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	batchReq := &pb.BatchGetLaptopsRequest{Ids: []string{laptop2.Id, missingID, laptop1.Id, laptop2.Id}}
+	batchRes, err := laptopClient.BatchGetLaptops(context.Background(), batchReq)
+	require.NoError(t, err)
+	require.Len(t, batchRes.GetLaptops(), 2)
+	requireSameLaptop(t, laptop2, batchRes.GetLaptops()[0])
+	requireSameLaptop(t, laptop1, batchRes.GetLaptops()[1])
+	require.Equal(t, []string{missingID}, batchRes.GetMissingIds())
+}
+
+func TestClientSearchLaptopPaginated(t *testing.T) {
+	t.Parallel()
+
+	filter := &pb.Filter{MaxPriceUsd: 5000}
+	store := service.NewInMemoryLaptopStore()
+	for i := 0; i < 7; i++ {
+		laptop := sample.NewLaptop()
+		laptop.PriceUsd = float64(1000 + 100*(i%4))
+		require.NoError(t, store.Save(laptop))
+	}
+
+	serverAddress := startTestLaptopServer(t, store, nil, service.NewInMemoryRatingStore())
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	searchPage := func(token string) ([]*pb.Laptop, string) {
+		req := &pb.SearchLaptopRequest{
+			Filter:     filter,
+			OrderBy:    pb.SearchLaptopRequest_PRICE,
+			Descending: true,
+			PageSize:   3,
+			PageToken:  token,
+		}
+		stream, err := laptopClient.SearchLaptop(context.Background(), req)
+		require.NoError(t, err)
+
+		var laptops []*pb.Laptop
+		var next string
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return laptops, next
+			}
+			require.NoError(t, err)
+			laptops = append(laptops, res.GetLaptop())
+			next = res.GetNextPageToken()
+		}
+	}
+
+	var found []*pb.Laptop
+	page, token := searchPage("")
+	require.Len(t, page, 3)
+	found = append(found, page...)
+
+	// a laptop sorted before the cursor must not shift the following pages
+	laptop := sample.NewLaptop()
+	laptop.PriceUsd = 4000
+	require.NoError(t, store.Save(laptop))
+
+	for token != "" {
+		page, token = searchPage(token)
+		found = append(found, page...)
+	}
+	require.Len(t, found, 7)
+
+	seen := make(map[string]bool)
+	for i, laptop := range found {
+		require.False(t, seen[laptop.Id])
+		seen[laptop.Id] = true
+		if i > 0 {
+			require.LessOrEqual(t, laptop.PriceUsd, found[i-1].PriceUsd)
+		}
+	}
+
+	stream, err := laptopClient.SearchLaptop(context.Background(), &pb.SearchLaptopRequest{Filter: filter, PageToken: "not a token"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClientSearchLaptopQuery(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	expectedIDs := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		laptop := sample.NewLaptop()
+		laptop.Brand = "Dell"
+		laptop.PriceUsd = 1500
+		laptop.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+		switch i {
+		case 0:
+			laptop.Brand = "Apple"
+		case 1:
+			laptop.PriceUsd = 2500
+		default:
+			expectedIDs[laptop.Id] = true
+		}
+		require.NoError(t, store.Save(laptop))
+	}
+
+	serverAddress := startTestLaptopServer(t, store, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	req := &pb.SearchLaptopRequest{Query: `brand in ("Dell", "Lenovo") and ram >= 16GB and price < 2000`}
+	stream, err := laptopClient.SearchLaptop(context.Background(), req)
 	require.NoError(t, err)
-	
-	for idx := 0; ; idx++ {
+
+	found := 0
+	for {
 		res, err := stream.Recv()
 		if err == io.EOF {
-			require.Equal(t, n, idx)
-			return
+			break
+		}
+		require.NoError(t, err)
+		require.Contains(t, expectedIDs, res.GetLaptop().GetId())
+		found++
+	}
+	require.Equal(t, len(expectedIDs), found)
+
+	req = &pb.SearchLaptopRequest{Query: `brand = "Dell" and ram >= 16`}
+	stream, err = laptopClient.SearchLaptop(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Contains(t, status.Convert(err).Message(), "column 27")
+}
+
+func TestClientWatchLaptops(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewWatchableLaptopStore(service.NewInMemoryLaptopStore())
+	serverAddress := startTestLaptopServer(t, store, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	filter := &pb.Filter{MaxPriceUsd: 10000, Brands: []string{"Dell"}}
+	recvEvents := func(startEpoch string, startRevision uint64, n int) []*pb.LaptopEvent {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := laptopClient.WatchLaptops(ctx, &pb.WatchLaptopsRequest{Filter: filter, StartEpoch: startEpoch, StartRevision: startRevision})
+		require.NoError(t, err)
+
+		var events []*pb.LaptopEvent
+		for len(events) < n {
+			res, err := stream.Recv()
+			require.NoError(t, err)
+			events = append(events, res.GetEvent())
 		}
+		return events
+	}
+
+	dell := sample.NewLaptop()
+	dell.Brand = "Dell"
+	apple := sample.NewLaptop()
+	apple.Brand = "Apple"
+
+	// the events carry the epoch of the store, a watch resumes from a revision of that epoch
+	_, subscription, err := store.Subscribe("", 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(dell))
+	epoch := (<-subscription.Changes).Event.GetEpoch()
+	require.NotEmpty(t, epoch)
+	store.Unsubscribe(subscription)
+	require.NoError(t, store.Save(apple))
+	update := proto.Clone(dell).(*pb.Laptop)
+	update.Brand = "Lenovo"
+	_, err = store.Update(update, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(dell.Id, 0))
+
+	// the apple laptop doesn't match, the dell laptop still matches before the update
+	events := recvEvents(epoch, 1, 1)
+	require.Equal(t, pb.LaptopEvent_UPDATED, events[0].GetType())
+	require.Equal(t, uint64(3), events[0].GetRevision())
+	require.Equal(t, "Lenovo", events[0].GetLaptop().GetBrand())
+
+	done := make(chan []*pb.LaptopEvent)
+	go func() {
+		done <- recvEvents(epoch, 4, 1)
+	}()
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Dell"
+	require.NoError(t, store.Save(laptop))
 
+	events = <-done
+	require.Equal(t, pb.LaptopEvent_CREATED, events[0].GetType())
+	require.Equal(t, uint64(5), events[0].GetRevision())
+	require.Equal(t, laptop.Id, events[0].GetLaptop().GetId())
+	require.Equal(t, epoch, events[0].GetEpoch())
+
+	// a revision ahead of the current one, or of another epoch such as before a restart, cannot be resumed
+	for _, req := range []*pb.WatchLaptopsRequest{
+		{StartEpoch: epoch, StartRevision: 100},
+		{StartEpoch: "previous", StartRevision: 1},
+		{StartRevision: 1},
+	} {
+		stream, err := laptopClient.WatchLaptops(context.Background(), req)
 		require.NoError(t, err)
-		require.Equal(t, laptop.GetId(), res.GetLaptopId())
-		require.Equal(t, uint32(idx+1), res.GetRatedCount())
-		require.Equal(t, averages[idx], res.GetAverageScore())
+		_, err = stream.Recv()
+		require.Equal(t, codes.OutOfRange, status.Code(err))
 	}
 }
+
+func TestClientDownloadImage(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	imageStore, err := service.NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := os.ReadFile("../tmp/laptop.jpg")
+	require.NoError(t, err)
+
+	upload, err := laptopClient.UploadImage(context.Background())
+	require.NoError(t, err)
+	err = upload.Send(&pb.UploadImageRequest{
+		Data: &pb.UploadImageRequest_Info{Info: &pb.ImageInfo{LaptopId: laptop.Id, ImageType: "jpg"}},
+	})
+	require.NoError(t, err)
+	err = upload.Send(&pb.UploadImageRequest{
+		Data: &pb.UploadImageRequest_ChunkData{ChunkData: imageData},
+	})
+	require.NoError(t, err)
+	uploadRes, err := upload.CloseAndRecv()
+	require.NoError(t, err)
+	digest := sha256.Sum256(imageData)
+	require.Equal(t, hex.EncodeToString(digest[:]), uploadRes.GetDigest())
+
+	listRes, err := laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: laptop.Id})
+	require.NoError(t, err)
+	require.Len(t, listRes.GetImages(), 1)
+	require.Equal(t, uploadRes.GetId(), listRes.GetImages()[0].GetId())
+	require.Equal(t, uint64(len(imageData)), listRes.GetImages()[0].GetSize())
+
+	download, err := laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: uploadRes.GetId()})
+	require.NoError(t, err)
+	res, err := download.Recv()
+	require.NoError(t, err)
+	require.Equal(t, laptop.Id, res.GetInfo().GetLaptopId())
+	require.Equal(t, "jpg", res.GetInfo().GetImageType())
+	require.Equal(t, "image/jpeg", res.GetInfo().GetMimeType())
+	require.NotZero(t, res.GetInfo().GetWidth())
+
+	var downloaded []byte
+	for {
+		res, err := download.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		downloaded = append(downloaded, res.GetChunkData()...)
+	}
+	require.Equal(t, imageData, downloaded)
+
+	// the 800px photo has renditions of 128 and 512 pixels
+	download, err = laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: uploadRes.GetId(), MaxSize: 600})
+	require.NoError(t, err)
+	res, err = download.Recv()
+	require.NoError(t, err)
+	require.Len(t, res.GetInfo().GetRenditions(), 2)
+	require.Equal(t, uint32(512), res.GetRendition().GetWidth())
+	downloaded = nil
+	for {
+		res, err := download.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		downloaded = append(downloaded, res.GetChunkData()...)
+	}
+	require.Equal(t, res.GetRendition().GetSize(), uint64(len(downloaded)))
+
+	download, err = laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: "missing"})
+	require.NoError(t, err)
+	_, err = download.Recv()
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientUploadImageInvalid(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	imageStore, err := service.NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := os.ReadFile("../tmp/laptop.jpg")
+	require.NoError(t, err)
+
+	upload := func(imageType string, data []byte) (*pb.UploadImageResponse, error) {
+		stream, err := laptopClient.UploadImage(context.Background())
+		require.NoError(t, err)
+		info := &pb.ImageInfo{LaptopId: laptop.Id, ImageType: imageType}
+		require.NoError(t, stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: info}}))
+		// the server may reject the info before the chunk is sent, CloseAndRecv returns its error
+		err = stream.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: data}})
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return stream.CloseAndRecv()
+	}
+
+	_, err = upload("jpg", []byte("definitely not an image"))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = upload("png", imageData)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = upload("exe", imageData)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the extension of a file name is accepted as the image type
+	res, err := upload(".JPEG", imageData)
+	require.NoError(t, err)
+	image, err := imageStore.Find(res.GetId())
+	require.NoError(t, err)
+	require.Equal(t, "jpg", image.Type)
+
+	// uploading the same content again returns the existing image
+	other, err := upload("jpg", imageData)
+	require.NoError(t, err)
+	require.Equal(t, res.GetId(), other.GetId())
+	require.Equal(t, res.GetDigest(), other.GetDigest())
+}
+
+func TestClientUploadImageResume(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	diskImageStore, err := service.NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	imageStore := &signalingImageStore{
+		ImageStore: diskImageStore,
+		written:    make(chan struct{}, 10),
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := os.ReadFile("../tmp/laptop.jpg")
+	require.NoError(t, err)
+	half := len(imageData) / 2
+	info := &pb.ImageInfo{LaptopId: laptop.Id, ImageType: "jpg", UploadId: uuid.New().String()}
+
+	_, err = laptopClient.GetUploadStatus(context.Background(), &pb.GetUploadStatusRequest{UploadId: info.UploadId})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// the connection breaks after the first half of the image
+	ctx, cancel := context.WithCancel(context.Background())
+	upload, err := laptopClient.UploadImage(ctx)
+	require.NoError(t, err)
+	require.NoError(t, upload.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: info}}))
+	require.NoError(t, upload.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: imageData[:half]}}))
+	<-imageStore.written
+	cancel()
+
+	var offset uint64
+	require.Eventually(t, func() bool {
+		res, err := laptopClient.GetUploadStatus(context.Background(), &pb.GetUploadStatusRequest{UploadId: info.UploadId})
+		offset = res.GetOffset()
+		return err == nil && offset == uint64(half)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the server rejects an offset other than the committed one once it has released the session
+	require.Eventually(t, func() bool {
+		upload, err := laptopClient.UploadImage(context.Background())
+		if err != nil {
+			return false
+		}
+		stale := &pb.ImageInfo{LaptopId: info.LaptopId, ImageType: info.ImageType, UploadId: info.UploadId}
+		err = upload.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: stale}})
+		if err != nil {
+			return false
+		}
+		_, err = upload.CloseAndRecv()
+		return status.Code(err) == codes.FailedPrecondition
+	}, 5*time.Second, 10*time.Millisecond)
+
+	upload, err = laptopClient.UploadImage(context.Background())
+	require.NoError(t, err)
+	info.Offset = offset
+	require.NoError(t, upload.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_Info{Info: info}}))
+	require.NoError(t, upload.Send(&pb.UploadImageRequest{Data: &pb.UploadImageRequest_ChunkData{ChunkData: imageData[half:]}}))
+	res, err := upload.CloseAndRecv()
+	require.NoError(t, err)
+	require.Equal(t, uint32(len(imageData)), res.GetSize())
+
+	image, err := imageStore.Find(res.GetId())
+	require.NoError(t, err)
+	uploaded, err := os.ReadFile(image.Path)
+	require.NoError(t, err)
+	require.Equal(t, imageData, uploaded)
+}
+
+// signalingImageStore signals every write of a resumable upload
+type signalingImageStore struct {
+	service.ImageStore
+	written chan struct{}
+}
+
+func (s *signalingImageStore) Resume(uploadId string, laptopId string, imageType string, offset int64) (service.ImageWriter, error) {
+	writer, err := s.ImageStore.Resume(uploadId, laptopId, imageType, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &signalingImageWriter{ImageWriter: writer, written: s.written}, nil
+}
+
+type signalingImageWriter struct {
+	service.ImageWriter
+	written chan struct{}
+}
+
+func (w *signalingImageWriter) Write(data []byte) (int, error) {
+	n, err := w.ImageWriter.Write(data)
+	w.written <- struct{}{}
+	return n, err
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-go/pb"
+	"grpc-go/units"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const defaultPriceBucketSize = 500
+
+var ErrUnknownFacetField = errors.New("unknown facet field")
+
+var (
+	defaultValueFacetFields   = []string{"brand", "cpu.brand", "screen.panel", "price_usd"}
+	defaultNumericFacetFields = []string{"price_usd", "release_year"}
+)
+
+// valueFacetFields return the values of a laptop counted by a value facet
+var valueFacetFields = map[string]func(laptop *pb.Laptop, bucketSize float64) []string{
+	"brand": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{laptop.GetBrand()}
+	},
+	"name": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{laptop.GetName()}
+	},
+	"cpu.brand": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{laptop.GetCpu().GetBrand()}
+	},
+	"gpu.brand": func(laptop *pb.Laptop, bucketSize float64) []string {
+		// count a laptop once per brand even if it has several GPUs of that brand
+		var brands []string
+		seen := make(map[string]bool)
+		for _, gpu := range laptop.GetGpus() {
+			if !seen[gpu.GetBrand()] {
+				seen[gpu.GetBrand()] = true
+				brands = append(brands, gpu.GetBrand())
+			}
+		}
+		return brands
+	},
+	"screen.panel": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{laptop.GetScreen().GetPanel().String()}
+	},
+	"keyboard.layout": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{laptop.GetKeyboard().GetLayout().String()}
+	},
+	"release_year": func(laptop *pb.Laptop, bucketSize float64) []string {
+		return []string{strconv.FormatFloat(laptop.GetReleaseYear(), 'f', -1, 64)}
+	},
+	"price_usd": func(laptop *pb.Laptop, bucketSize float64) []string {
+		lower := math.Floor(laptop.GetPriceUsd()/bucketSize) * bucketSize
+		return []string{fmt.Sprintf("%g-%g", lower, lower+bucketSize)}
+	},
+}
+
+// numericFacetFields return the value of a laptop aggregated by a numeric facet
+var numericFacetFields = map[string]func(laptop *pb.Laptop) float64{
+	"price_usd": func(laptop *pb.Laptop) float64 {
+		return laptop.GetPriceUsd()
+	},
+	"release_year": func(laptop *pb.Laptop) float64 {
+		return laptop.GetReleaseYear()
+	},
+	"cpu.number_cores": func(laptop *pb.Laptop) float64 {
+		return float64(laptop.GetCpu().GetNumberCores())
+	},
+	"cpu.min_ghz": func(laptop *pb.Laptop) float64 {
+		return laptop.GetCpu().GetMinGhz()
+	},
+	"ram_gb": func(laptop *pb.Laptop) float64 {
+		return float64(units.ToBit(laptop.GetRam())) / (1 << 33)
+	},
+	"weight_kg": func(laptop *pb.Laptop) float64 {
+		return units.WeightKg(laptop)
+	},
+	"screen.size_inch": func(laptop *pb.Laptop) float64 {
+		return float64(laptop.GetScreen().GetSizeInch())
+	},
+}
+
+// facetCollector accumulates the facets of the laptops passed to add
+type facetCollector struct {
+	bucketSize    float64
+	valueFields   []string
+	numericFields []string
+	total         uint64
+	counts        map[string]map[string]uint64
+	numeric       map[string]*pb.NumericFacet
+	sums          map[string]float64
+}
+
+func newFacetCollector(spec *pb.FacetSpec) (*facetCollector, error) {
+	collector := &facetCollector{
+		bucketSize:    spec.GetPriceBucketSize(),
+		valueFields:   spec.GetValueFields(),
+		numericFields: spec.GetNumericFields(),
+		counts:        make(map[string]map[string]uint64),
+		numeric:       make(map[string]*pb.NumericFacet),
+		sums:          make(map[string]float64),
+	}
+	if collector.bucketSize <= 0 {
+		collector.bucketSize = defaultPriceBucketSize
+	}
+	if len(collector.valueFields) == 0 && len(collector.numericFields) == 0 {
+		collector.valueFields = defaultValueFacetFields
+		collector.numericFields = defaultNumericFacetFields
+	}
+
+	for _, field := range collector.valueFields {
+		if valueFacetFields[field] == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFacetField, field)
+		}
+		collector.counts[field] = make(map[string]uint64)
+	}
+	for _, field := range collector.numericFields {
+		if numericFacetFields[field] == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFacetField, field)
+		}
+		collector.numeric[field] = &pb.NumericFacet{Field: field}
+	}
+	return collector, nil
+}
+
+func (c *facetCollector) add(laptop *pb.Laptop) error {
+	c.total++
+	for field, counts := range c.counts {
+		for _, value := range valueFacetFields[field](laptop, c.bucketSize) {
+			counts[value]++
+		}
+	}
+	for field, facet := range c.numeric {
+		value := numericFacetFields[field](laptop)
+		if facet.Count == 0 || value < facet.Min {
+			facet.Min = value
+		}
+		if facet.Count == 0 || value > facet.Max {
+			facet.Max = value
+		}
+		c.sums[field] += value
+		facet.Count++
+	}
+	return nil
+}
+
+func (c *facetCollector) result() *pb.Facets {
+	facets := &pb.Facets{Total: c.total}
+	for _, field := range c.valueFields {
+		facet := &pb.ValueFacet{Field: field}
+		for value, count := range c.counts[field] {
+			facet.Counts = append(facet.Counts, &pb.ValueFacet_Count{Value: value, Count: count})
+		}
+		sort.Slice(facet.Counts, func(i, j int) bool {
+			if facet.Counts[i].Count != facet.Counts[j].Count {
+				return facet.Counts[i].Count > facet.Counts[j].Count
+			}
+			return facet.Counts[i].Value < facet.Counts[j].Value
+		})
+		facets.ValueFacets = append(facets.ValueFacets, facet)
+	}
+	for _, field := range c.numericFields {
+		facet := c.numeric[field]
+		if facet.Count > 0 {
+			facet.Avg = c.sums[field] / float64(facet.Count)
+		}
+		facets.NumericFacets = append(facets.NumericFacets, facet)
+	}
+	return facets
+}
+
+func (m *InMemoryLaptopStore) Facets(ctx context.Context, filter *pb.Filter, spec *pb.FacetSpec) (*pb.Facets, error) {
+	collector, err := newFacetCollector(spec)
+	if err != nil {
+		return nil, err
+	}
+	err = m.Search(ctx, filter, collector.add)
+	if err != nil {
+		return nil, err
+	}
+	return collector.result(), nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"grpc-go/pb"
+	"grpc-go/units"
+	"math"
+	"sort"
+)
+
+// indexChunkSize is the maximum number of entries in one chunk of a sorted index
+const indexChunkSize = 512
+
+type indexEntry struct {
+	key float64
+	id  string
+}
+
+// less orders the entries by key with NaN first, as compareFloat does, and then by id
+func (e indexEntry) less(other indexEntry) bool {
+	if c := compareFloat(e.key, other.key); c != 0 {
+		return c < 0
+	}
+	return e.id < other.id
+}
+
+// sortedIndex keeps entries ordered by key and id. The entries are split into sorted chunks,
+// so an insert or a removal only moves the entries of one chunk.
+type sortedIndex struct {
+	chunks [][]indexEntry
+}
+
+// findChunk returns the chunk that contains the first entry for which pred is true,
+// pred must be false for a prefix of the entries and true for the rest
+func (idx *sortedIndex) findChunk(pred func(e indexEntry) bool) int {
+	return sort.Search(len(idx.chunks), func(c int) bool {
+		chunk := idx.chunks[c]
+		return pred(chunk[len(chunk)-1])
+	})
+}
+
+func (idx *sortedIndex) insert(e indexEntry) {
+	if len(idx.chunks) == 0 {
+		idx.chunks = [][]indexEntry{{e}}
+		return
+	}
+
+	c := idx.findChunk(func(other indexEntry) bool { return !other.less(e) })
+	if c == len(idx.chunks) {
+		c--
+	}
+	chunk := idx.chunks[c]
+	i := sort.Search(len(chunk), func(i int) bool { return !chunk[i].less(e) })
+	chunk = append(chunk, indexEntry{})
+	copy(chunk[i+1:], chunk[i:])
+	chunk[i] = e
+
+	if len(chunk) <= indexChunkSize {
+		idx.chunks[c] = chunk
+		return
+	}
+	half := len(chunk) / 2
+	left := append(make([]indexEntry, 0, indexChunkSize+1), chunk[:half]...)
+	right := append(make([]indexEntry, 0, indexChunkSize+1), chunk[half:]...)
+	idx.chunks = append(idx.chunks, nil)
+	copy(idx.chunks[c+2:], idx.chunks[c+1:])
+	idx.chunks[c] = left
+	idx.chunks[c+1] = right
+}
+
+func (idx *sortedIndex) remove(e indexEntry) {
+	c := idx.findChunk(func(other indexEntry) bool { return !other.less(e) })
+	if c == len(idx.chunks) {
+		return
+	}
+	chunk := idx.chunks[c]
+	i := sort.Search(len(chunk), func(i int) bool { return !chunk[i].less(e) })
+	if i == len(chunk) || chunk[i] != e {
+		return
+	}
+
+	if len(chunk) == 1 {
+		idx.chunks = append(idx.chunks[:c], idx.chunks[c+1:]...)
+		return
+	}
+	idx.chunks[c] = append(chunk[:i], chunk[i+1:]...)
+}
+
+// position of an entry in the index, as chunk and offset in the chunk
+type indexPosition struct {
+	chunk  int
+	offset int
+}
+
+// search returns the position of the first entry for which pred is true and the number of entries before it
+func (idx *sortedIndex) search(pred func(e indexEntry) bool) (indexPosition, int) {
+	c := idx.findChunk(pred)
+	rank := 0
+	for i := 0; i < c; i++ {
+		rank += len(idx.chunks[i])
+	}
+	if c == len(idx.chunks) {
+		return indexPosition{chunk: c}, rank
+	}
+	chunk := idx.chunks[c]
+	offset := sort.Search(len(chunk), func(i int) bool { return pred(chunk[i]) })
+	return indexPosition{chunk: c, offset: offset}, rank + offset
+}
+
+// keyRange returns the bounds of the entries with min <= key <= max and their number
+func (idx *sortedIndex) keyRange(min, max float64) (indexPosition, indexPosition, int) {
+	from, fromRank := idx.search(func(e indexEntry) bool { return e.key >= min })
+	to, toRank := idx.search(func(e indexEntry) bool { return e.key > max })
+	return from, to, toRank - fromRank
+}
+
+// ascend calls fn for every entry from the position from up to (excluding) the position to
+func (idx *sortedIndex) ascend(from, to indexPosition, fn func(e indexEntry) error) error {
+	for c := from.chunk; c < len(idx.chunks) && c <= to.chunk; c++ {
+		chunk := idx.chunks[c]
+		start, end := 0, len(chunk)
+		if c == from.chunk {
+			start = from.offset
+		}
+		if c == to.chunk {
+			end = to.offset
+		}
+		for i := start; i < end; i++ {
+			err := fn(chunk[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// laptopIndex is a secondary index on one numeric laptop field
+type laptopIndex struct {
+	name string
+	key  func(laptop *pb.Laptop) float64
+	// bounds returns the range of keys a laptop must have to match the filter,
+	// ok is false if the filter doesn't constrain the field
+	bounds func(filter *pb.Filter) (min float64, max float64, ok bool)
+	sortedIndex
+}
+
+func newLaptopIndexes() []*laptopIndex {
+	return []*laptopIndex{
+		{
+			name: "price",
+			key:  func(laptop *pb.Laptop) float64 { return laptop.GetPriceUsd() },
+			bounds: func(filter *pb.Filter) (float64, float64, bool) {
+				// max_price_usd applies even if it is unset
+				min := math.Inf(-1)
+				if filter.GetMinPriceUsd() > 0 {
+					min = filter.GetMinPriceUsd()
+				}
+				return min, filter.GetMaxPriceUsd(), true
+			},
+		},
+		{
+			name: "cpu_cores",
+			key:  func(laptop *pb.Laptop) float64 { return float64(laptop.GetCpu().GetNumberCores()) },
+			bounds: func(filter *pb.Filter) (float64, float64, bool) {
+				return float64(filter.GetMinCpuCores()), math.Inf(1), filter.GetMinCpuCores() > 0
+			},
+		},
+		{
+			name: "cpu_min_ghz",
+			key:  func(laptop *pb.Laptop) float64 { return laptop.GetCpu().GetMinGhz() },
+			bounds: func(filter *pb.Filter) (float64, float64, bool) {
+				return filter.GetMinCpuGhz(), math.Inf(1), filter.GetMinCpuGhz() > 0
+			},
+		},
+		{
+			name: "ram",
+			key:  func(laptop *pb.Laptop) float64 { return float64(units.ToBit(laptop.GetRam())) },
+			bounds: func(filter *pb.Filter) (float64, float64, bool) {
+				minRam := units.ToBit(filter.GetMinRam())
+				return float64(minRam), math.Inf(1), minRam > 0
+			},
+		},
+	}
+}
+
+func (index *laptopIndex) addLaptop(laptop *pb.Laptop) {
+	index.insert(indexEntry{key: index.key(laptop), id: laptop.GetId()})
+}
+
+func (index *laptopIndex) removeLaptop(laptop *pb.Laptop) {
+	index.remove(indexEntry{key: index.key(laptop), id: laptop.GetId()})
+}
+
+// searchPlan is the index range a search iterates instead of scanning every laptop
+type searchPlan struct {
+	index *laptopIndex
+	from  indexPosition
+	to    indexPosition
+	count int
+}
+
+// planSearch picks the index with the fewest entries in the range of the filter,
+// it returns nil if no index narrows the search down
+func planSearch(indexes []*laptopIndex, filter *pb.Filter, total int) *searchPlan {
+	var best *searchPlan
+	for _, index := range indexes {
+		min, max, ok := index.bounds(filter)
+		if !ok {
+			continue
+		}
+		from, to, count := index.keyRange(min, max)
+		if best == nil || count < best.count {
+			best = &searchPlan{index: index, from: from, to: to, count: count}
+		}
+	}
+	if best == nil || best.count >= total {
+		return nil
+	}
+	return best
+}
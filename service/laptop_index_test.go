@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"grpc-go/pb"
+	"grpc-go/sample"
+	"sort"
+	"testing"
+)
+
+func newIndexTestStore(t testing.TB, n int) *InMemoryLaptopStore {
+	store := newInMemoryLaptopStore()
+	for i := 0; i < n; i++ {
+		require.NoError(t, store.Save(sample.NewLaptop()))
+	}
+	return store
+}
+
+func collectIDs(t testing.TB, search func(found func(laptop *pb.Laptop) error) error) []string {
+	var ids []string
+	err := search(func(laptop *pb.Laptop) error {
+		ids = append(ids, laptop.Id)
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(ids)
+	return ids
+}
+
+func TestInMemoryLaptopStore_IndexedSearch(t *testing.T) {
+	t.Parallel()
+
+	store := newIndexTestStore(t, 3000)
+	ctx := context.Background()
+
+	// change and delete some laptops so the indexes must follow
+	i := 0
+	for id, laptop := range store.data {
+		switch i % 3 {
+		case 0:
+			other := sample.NewLaptop()
+			other.Id = id
+			_, err := store.Update(other, laptop.Version)
+			require.NoError(t, err)
+		case 1:
+			require.NoError(t, store.Delete(id, 0))
+		}
+		i++
+		if i == 600 {
+			break
+		}
+	}
+
+	filters := []*pb.Filter{
+		{MaxPriceUsd: 2000},
+		{MinPriceUsd: 2500, MaxPriceUsd: 2600},
+		{MinCpuCores: 7},
+		{MinCpuGhz: 3.3},
+		{MinRam: &pb.Memory{Value: 60, Unit: pb.Memory_GIGABYTE}},
+		{MaxPriceUsd: 3000, MinCpuCores: 4, MinCpuGhz: 2.5, MinRam: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}},
+		{MinPriceUsd: 3000, MaxPriceUsd: 2000},
+		{Brands: []string{"Dell"}},
+	}
+	for _, filter := range filters {
+		indexed := collectIDs(t, func(found func(laptop *pb.Laptop) error) error {
+			return store.Search(ctx, filter, found)
+		})
+		scanned := collectIDs(t, func(found func(laptop *pb.Laptop) error) error {
+			return store.scan(ctx, filter, found)
+		})
+		require.Equal(t, scanned, indexed, "filter: %v", filter)
+	}
+
+	for _, index := range store.indexes {
+		count := 0
+		var last *indexEntry
+		for _, chunk := range index.chunks {
+			require.NotEmpty(t, chunk)
+			require.LessOrEqual(t, len(chunk), indexChunkSize)
+			for j := range chunk {
+				if last != nil {
+					require.True(t, last.less(chunk[j]), index.name)
+				}
+				last = &chunk[j]
+				count++
+			}
+		}
+		require.Equal(t, len(store.data), count, index.name)
+	}
+}
+
+func BenchmarkInMemoryLaptopStore_Search(b *testing.B) {
+	filter := &pb.Filter{
+		MinPriceUsd: 2000,
+		MaxPriceUsd: 2020,
+		MinCpuCores: 4,
+		MinRam:      &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE},
+	}
+	ctx := context.Background()
+	noop := func(laptop *pb.Laptop) error { return nil }
+
+	for _, n := range []int{10000, 100000} {
+		store := newIndexTestStore(b, n)
+
+		b.Run(fmt.Sprintf("scan_%dk", n/1000), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				store.mutex.RLock()
+				err := store.scan(ctx, filter, noop)
+				store.mutex.RUnlock()
+				require.NoError(b, err)
+			}
+		})
+		b.Run(fmt.Sprintf("indexed_%dk", n/1000), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := store.Search(ctx, filter, noop)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,27 +8,58 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"grpc-go/pb"
+	"grpc-go/query"
+	"grpc-go/units"
 	"io"
 	"log"
+	"math"
+	"os"
+	"sort"
 )
 
-const maxImageSize = 1 << 20
+const (
+	// maxImageSize limits uploads, they are streamed to disk so it doesn't bound the memory use
+	maxImageSize = 512 << 20
+	// imageChunkSize is the size of the chunks DownloadImage sends
+	imageChunkSize = 64 << 10
+	// maxBatchGetSize limits the number of ids in a single BatchGetLaptops request
+	maxBatchGetSize = 1000
+	// defaultTopRatedLimit and maxTopRatedLimit bound the number of laptops ListTopRatedLaptops returns
+	defaultTopRatedLimit = 10
+	maxTopRatedLimit     = 100
+)
 
 type LaptopServer struct {
 	laptopStore LaptopStore
 	imageStore  ImageStore
 	ratingStore RateStore
+	ratings     RatingConfig
+	// imageGC is set if the laptops are persisted, so the images of unknown laptops can be deleted
+	imageGC bool
 	pb.UnimplementedLaptopServiceServer
 }
 
 func NewLaptopServer(laptopStore LaptopStore, imageStore ImageStore, ratingStore RateStore) *LaptopServer {
+	return NewLaptopServerWithRatingConfig(laptopStore, imageStore, ratingStore, DefaultRatingConfig)
+}
+
+// NewLaptopServerWithRatingConfig returns a server that accepts the scores on the scale of the config
+// and ranks the laptops by their Bayesian average with the prior of the config
+func NewLaptopServerWithRatingConfig(laptopStore LaptopStore, imageStore ImageStore, ratingStore RateStore, ratings RatingConfig) *LaptopServer {
 	return &LaptopServer{
 		laptopStore: laptopStore,
 		imageStore:  imageStore,
 		ratingStore: ratingStore,
+		ratings:     ratings,
 	}
 }
 
+// EnableImageGC lets the CollectImageGarbage RPC delete images, only for a persistent laptop store:
+// the images outlive laptops kept in memory, and would all be deleted after a restart
+func (s *LaptopServer) EnableImageGC() {
+	s.imageGC = true
+}
+
 func (s *LaptopServer) CreateLaptop(ctx context.Context, req *pb.CreateLaptopRequest) (*pb.CreateLaptopResponse, error) {
 	// 获取request的laptop对象，判断是否有id，没有则生成一个
 	laptop := req.GetLaptop()
@@ -45,18 +75,18 @@ func (s *LaptopServer) CreateLaptop(ctx context.Context, req *pb.CreateLaptopReq
 		}
 		laptop.Id = id.String()
 	}
-	err := contextErr(ctx)
+	err := validateLaptopNumbers(laptop)
+	if err != nil {
+		return nil, err
+	}
+	err = contextErr(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	err = s.laptopStore.Save(laptop)
 	if err != nil {
-		code := codes.Internal
-		if errors.Is(err, ErrAlreadyExist) {
-			code = codes.AlreadyExists
-		}
-		return nil, status.Errorf(code, "cannot save laptop to the store: %v", err)
+		return nil, status.Errorf(storeErrorCode(err), "cannot save laptop to the store: %v", err)
 	}
 
 	log.Printf("saved laptop with id: %s", laptop.Id)
@@ -66,6 +96,164 @@ func (s *LaptopServer) CreateLaptop(ctx context.Context, req *pb.CreateLaptopReq
 	return res, nil
 }
 
+// validateLaptopNumbers rejects the NaN and infinite values of the indexed and filtered fields
+func validateLaptopNumbers(laptop *pb.Laptop) error {
+	fields := []struct {
+		name  string
+		value float64
+	}{
+		{"price_usd", laptop.GetPriceUsd()},
+		{"release_year", laptop.GetReleaseYear()},
+		{"weight", units.WeightKg(laptop)},
+		{"cpu.min_ghz", laptop.GetCpu().GetMinGhz()},
+		{"cpu.max_ghz", laptop.GetCpu().GetMaxGhz()},
+	}
+	for _, field := range fields {
+		if math.IsNaN(field.value) || math.IsInf(field.value, 0) {
+			return status.Errorf(codes.InvalidArgument, "laptop %s is not a finite number: %v", field.name, field.value)
+		}
+	}
+	return nil
+}
+
+func (s *LaptopServer) GetLaptop(ctx context.Context, req *pb.GetLaptopRequest) (*pb.GetLaptopResponse, error) {
+	laptopID := req.GetId()
+	if len(laptopID) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop ID is required")
+	}
+
+	err := contextErr(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	laptop, err := s.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop id %s doesn't exist", laptopID)
+	}
+
+	res := &pb.GetLaptopResponse{
+		Laptop: laptop,
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) BatchGetLaptops(ctx context.Context, req *pb.BatchGetLaptopsRequest) (*pb.BatchGetLaptopsResponse, error) {
+	ids := req.GetIds()
+	if len(ids) > maxBatchGetSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many laptop ids: %d > %d", len(ids), maxBatchGetSize)
+	}
+	log.Printf("receive a batch-get-laptops request for %d laptops", len(ids))
+
+	res := &pb.BatchGetLaptopsResponse{}
+	seen := make(map[string]bool, len(ids))
+	for _, laptopID := range ids {
+		if seen[laptopID] {
+			continue
+		}
+		seen[laptopID] = true
+
+		err := contextErr(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		laptop, err := s.laptopStore.Find(laptopID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+		}
+		if laptop == nil {
+			res.MissingIds = append(res.MissingIds, laptopID)
+			continue
+		}
+		res.Laptops = append(res.Laptops, laptop)
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) UpdateLaptop(ctx context.Context, req *pb.UpdateLaptopRequest) (*pb.UpdateLaptopResponse, error) {
+	laptop := req.GetLaptop()
+	if len(laptop.GetId()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop ID is required")
+	}
+	log.Printf("receive an update-laptop request for laptop %s with mask %v", laptop.GetId(), req.GetUpdateMask().GetPaths())
+
+	err := contextErr(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.laptopStore.Find(laptop.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if current == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop id %s doesn't exist", laptop.GetId())
+	}
+	if req.GetExpectedVersion() != 0 && req.GetExpectedVersion() != current.GetVersion() {
+		return nil, status.Errorf(codes.Aborted, "laptop version is %d, expected %d", current.GetVersion(), req.GetExpectedVersion())
+	}
+
+	merged, err := applyUpdateMask(current, laptop, req.GetUpdateMask())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid update mask: %v", err)
+	}
+	err = validateLaptopNumbers(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	// pass the version the mask was applied to, so a concurrent change in between is detected
+	updated, err := s.laptopStore.Update(merged, current.GetVersion())
+	if err != nil {
+		return nil, status.Errorf(storeErrorCode(err), "cannot update laptop in the store: %v", err)
+	}
+
+	log.Printf("updated laptop with id: %s to version %d", updated.Id, updated.Version)
+	res := &pb.UpdateLaptopResponse{
+		Laptop: updated,
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) DeleteLaptop(ctx context.Context, req *pb.DeleteLaptopRequest) (*pb.DeleteLaptopResponse, error) {
+	laptopID := req.GetId()
+	if len(laptopID) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop ID is required")
+	}
+	log.Printf("receive a delete-laptop request for laptop %s", laptopID)
+
+	err := contextErr(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.laptopStore.Delete(laptopID, req.GetExpectedVersion())
+	if err != nil {
+		return nil, status.Errorf(storeErrorCode(err), "cannot delete laptop from the store: %v", err)
+	}
+
+	log.Printf("deleted laptop with id: %s", laptopID)
+	return &pb.DeleteLaptopResponse{}, nil
+}
+
+// storeErrorCode maps an error returned by a store to a gRPC status code
+func storeErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrAlreadyExist):
+		return codes.AlreadyExists
+	case errors.Is(err, ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, ErrVersionMismatch):
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
+
 func contextErr(ctx context.Context) error {
 	// 判断请求上下文是否取消或者超时了
 	switch ctx.Err() {
@@ -81,25 +269,176 @@ func contextErr(ctx context.Context) error {
 
 func (s *LaptopServer) SearchLaptop(req *pb.SearchLaptopRequest, stream pb.LaptopService_SearchLaptopServer) error {
 	filter := req.GetFilter()
-	log.Printf("receive a search-laptop request with filter: %v\n", filter)
+	log.Printf("receive a search-laptop request with filter: %v, query: %q, order by: %v, page size: %d\n",
+		filter, req.GetQuery(), req.GetOrderBy(), req.GetPageSize())
+
+	var token *pageToken
+	if len(req.GetPageToken()) > 0 {
+		var err error
+		token, err = decodePageToken(req.GetPageToken())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		if token.OrderBy != req.GetOrderBy() || token.Descending != req.GetDescending() {
+			return status.Errorf(codes.InvalidArgument, "page token was issued for another order")
+		}
+	}
+
+	var expr query.Expr
+	if len(req.GetQuery()) > 0 {
+		var err error
+		expr, err = query.Parse(req.GetQuery())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+		}
+		// the query alone selects the laptops if the filter is unset
+		filter = filterOrAll(filter)
+	}
 
+	var matches []sortedLaptop
 	err := s.laptopStore.Search(stream.Context(), filter, func(laptop *pb.Laptop) error {
-		res := &pb.SearchLaptopResponse{
-			Laptop: laptop,
+		if expr != nil && !expr.Eval(laptop) {
+			return nil
 		}
-		err := stream.Send(res)
+		key, err := s.sortKey(laptop, req.GetOrderBy())
 		if err != nil {
 			return err
 		}
-		fmt.Printf("send laptop with id: %s\n", laptop.Id)
+		matches = append(matches, sortedLaptop{laptop: laptop, key: key})
 		return nil
 	})
 	if err != nil {
 		return status.Errorf(codes.Internal, "unexpected err: %v", err)
 	}
+
+	page, more := paginate(matches, req.GetDescending(), token, req.GetPageSize())
+	for i, match := range page {
+		res := &pb.SearchLaptopResponse{
+			Laptop: match.laptop,
+		}
+		if i < len(page)-1 || more {
+			res.NextPageToken = encodePageToken(pageToken{
+				OrderBy:    req.GetOrderBy(),
+				Descending: req.GetDescending(),
+				KeyBits:    math.Float64bits(match.key),
+				ID:         match.laptop.Id,
+			})
+		}
+		err := stream.Send(res)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unexpected err: %v", err)
+		}
+		fmt.Printf("send laptop with id: %s\n", match.laptop.Id)
+	}
 	return nil
 }
 
+// sortKey returns the value of the laptop that the search results are ordered by
+func (s *LaptopServer) sortKey(laptop *pb.Laptop, orderBy pb.SearchLaptopRequest_OrderBy) (float64, error) {
+	switch orderBy {
+	case pb.SearchLaptopRequest_PRICE:
+		return laptop.GetPriceUsd(), nil
+	case pb.SearchLaptopRequest_RELEASE_YEAR:
+		return laptop.GetReleaseYear(), nil
+	case pb.SearchLaptopRequest_CPU_GHZ:
+		return laptop.GetCpu().GetMinGhz(), nil
+	case pb.SearchLaptopRequest_RAM:
+		return float64(units.ToBit(laptop.GetRam())), nil
+	case pb.SearchLaptopRequest_AVERAGE_RATING:
+		if s.ratingStore == nil {
+			return 0, nil
+		}
+		rating, err := s.ratingStore.Find(laptop.GetId())
+		if err != nil {
+			return 0, fmt.Errorf("cannot find rating: %w", err)
+		}
+		if rating == nil {
+			return 0, nil
+		}
+		return rating.Average(), nil
+	default:
+		// ordered by id only
+		return 0, nil
+	}
+}
+
+func (s *LaptopServer) GetLaptopFacets(ctx context.Context, req *pb.GetLaptopFacetsRequest) (*pb.GetLaptopFacetsResponse, error) {
+	log.Printf("receive a get-laptop-facets request with filter: %v, spec: %v", req.GetFilter(), req.GetSpec())
+
+	err := contextErr(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.laptopStore.Facets(ctx, filterOrAll(req.GetFilter()), req.GetSpec())
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrUnknownFacetField) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Errorf(code, "cannot compute laptop facets: %v", err)
+	}
+
+	res := &pb.GetLaptopFacetsResponse{
+		Facets: facets,
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) WatchLaptops(req *pb.WatchLaptopsRequest, stream pb.LaptopService_WatchLaptopsServer) error {
+	watcher, ok := s.laptopStore.(LaptopWatcher)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "laptop store cannot be watched")
+	}
+	filter := filterOrAll(req.GetFilter())
+	log.Printf("receive a watch-laptops request with filter: %v from revision %d of epoch %q", filter, req.GetStartRevision(), req.GetStartEpoch())
+
+	backlog, subscription, err := watcher.Subscribe(req.GetStartEpoch(), req.GetStartRevision())
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrRevisionUnavailable) {
+			code = codes.OutOfRange
+		}
+		return status.Errorf(code, "cannot watch laptops: %v", err)
+	}
+	defer watcher.Unsubscribe(subscription)
+
+	revision := req.GetStartRevision()
+	send := func(change *LaptopChange) error {
+		revision = change.Event.GetRevision()
+		if !isQualified(filter, change.Event.GetLaptop()) &&
+			(change.Previous == nil || !isQualified(filter, change.Previous)) {
+			return nil
+		}
+		err := stream.Send(&pb.WatchLaptopsResponse{Event: change.Event})
+		if err != nil {
+			return status.Errorf(codes.Unknown, "cannot send laptop event: %v", err)
+		}
+		return nil
+	}
+
+	for _, change := range backlog {
+		err := send(change)
+		if err != nil {
+			return logError(err)
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return contextErr(stream.Context())
+		case change, ok := <-subscription.Changes:
+			if !ok {
+				return logError(status.Errorf(codes.ResourceExhausted, "watcher is too slow, resume from revision %d", revision))
+			}
+			err := send(change)
+			if err != nil {
+				return logError(err)
+			}
+		}
+	}
+}
+
 func (s *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) error {
 	req, err := stream.Recv()
 	if err != nil {
@@ -116,8 +455,17 @@ func (s *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) er
 	if laptop == nil {
 		return logError(status.Errorf(codes.InvalidArgument, "laptop id %s doesn't exist", laptopId))
 	}
-	imageData := bytes.Buffer{}
-	imageSize := 0
+	imageType, err = normalizeImageType(imageType)
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+	imageWriter, imageSize, err := s.openImageWriter(laptopId, imageType, req.GetInfo())
+	if err != nil {
+		return err
+	}
+	// keeps the data of a resumable upload that doesn't complete and drops the others
+	defer imageWriter.Suspend()
+
 	for {
 		err = contextErr(stream.Context())
 		if err != nil {
@@ -133,25 +481,33 @@ func (s *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) er
 			return logError(status.Errorf(codes.Unknown, "cannot receive chunk data: %v", err))
 		}
 		chunk := req.GetChunkData()
-		size := len(chunk)
-		imageSize += size
+		imageSize += int64(len(chunk))
 		if imageSize > maxImageSize {
+			imageWriter.Abort()
 			return logError(status.Errorf(codes.InvalidArgument, "image is to large: %d > %d", imageSize, maxImageSize))
 		}
-		_, err = imageData.Write(chunk)
+		_, err = imageWriter.Write(chunk)
+		if errors.Is(err, ErrInvalidImage) {
+			imageWriter.Abort()
+			return logError(status.Errorf(codes.InvalidArgument, "cannot save image: %v", err))
+		}
 		if err != nil {
-			logError(status.Errorf(codes.Internal, "cannot write chunk data: %v", err))
+			return logError(status.Errorf(codes.Internal, "cannot write chunk data: %v", err))
 		}
 	}
 
-	imageID, err := s.imageStore.Save(laptopId, imageType, imageData)
+	image, err := imageWriter.Commit()
+	if errors.Is(err, ErrInvalidImage) {
+		return logError(status.Errorf(codes.InvalidArgument, "cannot save image: %v", err))
+	}
 	if err != nil {
 		return logError(status.Errorf(codes.Internal, "cannot save image to the store: %v", err))
 	}
 
 	res := &pb.UploadImageResponse{
-		Id:   imageID,
-		Size: uint32(imageSize),
+		Id:     image.Id,
+		Size:   uint32(imageSize),
+		Digest: image.Digest,
 	}
 
 	err = stream.SendAndClose(res)
@@ -159,10 +515,205 @@ func (s *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) er
 		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
 	}
 
-	log.Printf("saved image with id: %s, size: %d", imageID, imageSize)
+	log.Printf("saved image with id: %s, size: %d, digest: %s", image.Id, imageSize, image.Digest)
 	return nil
 }
 
+// openImageWriter starts a new image, or continues the resumable upload named by the info
+// and returns the number of bytes already received
+func (s *LaptopServer) openImageWriter(laptopId string, imageType string, info *pb.ImageInfo) (ImageWriter, int64, error) {
+	uploadId := info.GetUploadId()
+	if uploadId == "" {
+		imageWriter, err := s.imageStore.Create(laptopId, imageType)
+		if err != nil {
+			return nil, 0, logError(status.Errorf(codes.Internal, "cannot create image in the store: %v", err))
+		}
+		return imageWriter, 0, nil
+	}
+
+	_, err := uuid.Parse(uploadId)
+	if err != nil {
+		return nil, 0, logError(status.Errorf(codes.InvalidArgument, "upload id is not a valid UUID: %v", err))
+	}
+	offset := int64(info.GetOffset())
+	imageWriter, err := s.imageStore.Resume(uploadId, laptopId, imageType, offset)
+	if errors.Is(err, ErrUploadInProgress) {
+		return nil, 0, logError(status.Errorf(codes.Aborted, "upload %s is already in progress", uploadId))
+	}
+	if errors.Is(err, ErrUploadMismatch) {
+		return nil, 0, logError(status.Errorf(codes.InvalidArgument, "cannot resume upload %s: %v", uploadId, err))
+	}
+	if errors.Is(err, ErrUploadOffset) {
+		return nil, 0, logError(status.Errorf(codes.FailedPrecondition, "cannot resume upload %s: %v", uploadId, err))
+	}
+	if err != nil {
+		return nil, 0, logError(status.Errorf(codes.Internal, "cannot resume upload %s: %v", uploadId, err))
+	}
+	log.Printf("resume upload %s at offset %d", uploadId, offset)
+	return imageWriter, offset, nil
+}
+
+func (s *LaptopServer) GetUploadStatus(ctx context.Context, req *pb.GetUploadStatusRequest) (*pb.GetUploadStatusResponse, error) {
+	uploadId := req.GetUploadId()
+	_, err := uuid.Parse(uploadId)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "upload id is not a valid UUID: %v", err))
+	}
+
+	upload, err := s.imageStore.UploadStatus(uploadId)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get upload status: %v", err))
+	}
+	if upload == nil {
+		return nil, status.Errorf(codes.NotFound, "upload %s doesn't exist", uploadId)
+	}
+
+	res := &pb.GetUploadStatusResponse{
+		UploadId:  upload.UploadId,
+		LaptopId:  upload.LaptopId,
+		ImageType: upload.Type,
+		Offset:    uint64(upload.Offset),
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) ListImages(ctx context.Context, req *pb.ListImagesRequest) (*pb.ListImagesResponse, error) {
+	laptopId := req.GetLaptopId()
+	log.Printf("receive a list-images request for laptop %s", laptopId)
+
+	laptop, err := s.laptopStore.Find(laptopId)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find laptop: %v", err))
+	}
+	if laptop == nil {
+		return nil, logError(status.Errorf(codes.NotFound, "laptop id %s doesn't exist", laptopId))
+	}
+
+	images, err := s.imageStore.List(laptopId)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list images: %v", err))
+	}
+
+	res := &pb.ListImagesResponse{}
+	for _, image := range images {
+		res.Images = append(res.Images, toPbImageInfo(image))
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) DownloadImage(req *pb.DownloadImageRequest, stream pb.LaptopService_DownloadImageServer) error {
+	imageId := req.GetImageId()
+	log.Printf("receive a download-image request for image %s", imageId)
+
+	image, err := s.imageStore.Find(imageId)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot find image: %v", err))
+	}
+	if image == nil {
+		return logError(status.Errorf(codes.NotFound, "image id %s doesn't exist", imageId))
+	}
+
+	path := image.Path
+	size := image.Size
+	info := &pb.DownloadImageResponse{
+		Data: &pb.DownloadImageResponse_Info{Info: toPbImageInfo(image)},
+	}
+	rendition := image.Rendition(int(req.GetMaxSize()))
+	if rendition != nil {
+		path = rendition.Path
+		size = rendition.Size
+		info.Rendition = toPbImageRendition(rendition)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot open image file: %v", err))
+	}
+	defer file.Close()
+
+	err = stream.Send(info)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send image info: %v", err))
+	}
+
+	buffer := make([]byte, imageChunkSize)
+	for {
+		err = contextErr(stream.Context())
+		if err != nil {
+			return err
+		}
+		n, err := file.Read(buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot read image file: %v", err))
+		}
+		err = stream.Send(&pb.DownloadImageResponse{
+			Data: &pb.DownloadImageResponse_ChunkData{ChunkData: buffer[:n]},
+		})
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot send chunk data: %v", err))
+		}
+	}
+
+	log.Printf("sent image with id: %s, size: %d", imageId, size)
+	return nil
+}
+
+func (s *LaptopServer) CollectImageGarbage(ctx context.Context, req *pb.CollectImageGarbageRequest) (*pb.CollectImageGarbageResponse, error) {
+	collector, ok := s.imageStore.(ImageCollector)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "image store cannot collect garbage")
+	}
+	log.Print("receive a collect-image-garbage request")
+	if !s.imageGC {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot collect image garbage: laptops are not persisted")
+	}
+
+	stats, err := CollectImageGarbage(collector, s.laptopStore)
+	if errors.Is(err, ErrEmptyLaptopStore) {
+		return nil, logError(status.Errorf(codes.FailedPrecondition, "cannot collect image garbage: %v", err))
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot collect image garbage: %v", err))
+	}
+
+	log.Printf("image gc deleted %d images, removed %d files, freed %d bytes", stats.DeletedImages, stats.RemovedFiles, stats.FreedBytes)
+	res := &pb.CollectImageGarbageResponse{
+		DeletedImages: uint32(stats.DeletedImages),
+		RemovedFiles:  uint32(stats.RemovedFiles),
+		FreedBytes:    stats.FreedBytes,
+	}
+	return res, nil
+}
+
+func toPbImageInfo(image *ImageInfo) *pb.ImageInfo {
+	res := &pb.ImageInfo{
+		Id:        image.Id,
+		LaptopId:  image.LaptopId,
+		ImageType: image.Type,
+		Size:      uint64(image.Size),
+		MimeType:  image.MimeType,
+		Digest:    image.Digest,
+		Width:     uint32(image.Width),
+		Height:    uint32(image.Height),
+	}
+	for i := range image.Renditions {
+		res.Renditions = append(res.Renditions, toPbImageRendition(&image.Renditions[i]))
+	}
+	return res
+}
+
+func toPbImageRendition(rendition *ImageRendition) *pb.ImageRendition {
+	return &pb.ImageRendition{
+		Width:    uint32(rendition.Width),
+		Height:   uint32(rendition.Height),
+		MimeType: rendition.MimeType,
+		Size:     uint64(rendition.Size),
+	}
+}
+
 func logError(err error) error {
 	if err != nil {
 		log.Println(err)
@@ -171,6 +722,11 @@ func logError(err error) error {
 }
 
 func (s *LaptopServer) RateLaptop(stream pb.LaptopService_RateLaptopServer) error {
+	username, err := authenticatedUsername(stream.Context())
+	if err != nil {
+		return logError(err)
+	}
+
 	for {
 		err := contextErr(stream.Context())
 		if err != nil {
@@ -188,31 +744,174 @@ func (s *LaptopServer) RateLaptop(stream pb.LaptopService_RateLaptopServer) erro
 
 		laptopID := req.GetLaptopId()
 		score := req.GetScore()
-		log.Printf("received a rate-laptop request: id = %s, score = %.2f", laptopID, score)
+		log.Printf("received a rate-laptop request from %s: id = %s, score = %.2f", username, laptopID, score)
 
-		found, err := s.laptopStore.Find(laptopID)
+		res, err := s.rateLaptop(laptopID, username, score)
 		if err != nil {
-			return logError(status.Errorf(codes.Internal, "cannot find laptop: %v", err))
-		}
-		if found == nil {
-			return logError(status.Errorf(codes.NotFound, "laptopId %s is not found", laptopID))
+			return logError(err)
 		}
 
-		rating, err := s.ratingStore.Add(laptopID, score)
+		err = stream.Send(res)
 		if err != nil {
-			return logError(status.Errorf(codes.Internal, "cannot add rating to the store: %v", err))
+			return logError(status.Errorf(codes.Unknown, "cannot send stream response: %v", err))
 		}
+	}
+	return nil
+}
 
-		res := &pb.RateLaptopResponse{
-			LaptopId:     laptopID,
-			RatedCount:   rating.Count,
-			AverageScore: rating.Sum / float64(rating.Count),
-		}
+// rateLaptop saves the score, a request that cannot be satisfied is answered with an error in the response
+// so that it doesn't end the stream
+func (s *LaptopServer) rateLaptop(laptopID string, username string, score float64) (*pb.RateLaptopResponse, error) {
+	err := s.ratings.Scale.Validate(score)
+	if err != nil {
+		log.Printf("reject rating of laptop %s: %v", laptopID, err)
+		return &pb.RateLaptopResponse{LaptopId: laptopID, Error: err.Error()}, nil
+	}
 
-		err = stream.Send(res)
+	found, err := s.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop: %v", err)
+	}
+	if found == nil {
+		log.Printf("reject rating of laptop %s: not found", laptopID)
+		return &pb.RateLaptopResponse{LaptopId: laptopID, Error: fmt.Sprintf("laptopId %s is not found", laptopID)}, nil
+	}
+
+	rating, err := s.ratingStore.Add(laptopID, username, score)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot add rating to the store: %v", err)
+	}
+
+	res := &pb.RateLaptopResponse{
+		LaptopId:     laptopID,
+		RatedCount:   rating.Count,
+		AverageScore: rating.Average(),
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) RetractRating(ctx context.Context, req *pb.RetractRatingRequest) (*pb.RetractRatingResponse, error) {
+	username, err := authenticatedUsername(ctx)
+	if err != nil {
+		return nil, logError(err)
+	}
+	laptopID := req.GetLaptopId()
+	log.Printf("receive a retract-rating request from %s: id = %s", username, laptopID)
+
+	rating, err := s.ratingStore.Remove(laptopID, username)
+	if errors.Is(err, ErrNotFound) {
+		return nil, logError(status.Errorf(codes.NotFound, "%s didn't rate laptop %s", username, laptopID))
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot remove rating from the store: %v", err))
+	}
+
+	res := &pb.RetractRatingResponse{
+		LaptopId:     laptopID,
+		RatedCount:   rating.Count,
+		AverageScore: rating.Average(),
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) GetLaptopRating(ctx context.Context, req *pb.GetLaptopRatingRequest) (*pb.GetLaptopRatingResponse, error) {
+	laptopID := req.GetLaptopId()
+	log.Printf("receive a get-laptop-rating request for laptop %s", laptopID)
+
+	laptop, err := s.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find laptop: %v", err))
+	}
+	if laptop == nil {
+		return nil, logError(status.Errorf(codes.NotFound, "laptop id %s doesn't exist", laptopID))
+	}
+
+	rating, err := s.ratingStore.Find(laptopID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find rating: %v", err))
+	}
+
+	res := &pb.GetLaptopRatingResponse{
+		Rating: s.toPbLaptopRating(laptopID, rating),
+	}
+	return res, nil
+}
+
+func (s *LaptopServer) ListTopRatedLaptops(ctx context.Context, req *pb.ListTopRatedLaptopsRequest) (*pb.ListTopRatedLaptopsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultTopRatedLimit
+	}
+	if limit > maxTopRatedLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds %d", limit, maxTopRatedLimit)
+	}
+	log.Printf("receive a list-top-rated-laptops request with filter: %v, limit: %d", req.GetFilter(), limit)
+
+	var rated []*pb.RatedLaptop
+	err := s.laptopStore.Search(ctx, filterOrAll(req.GetFilter()), func(laptop *pb.Laptop) error {
+		rating, err := s.ratingStore.Find(laptop.GetId())
 		if err != nil {
-			return logError(status.Errorf(codes.Unknown, "cannot send stream response: %v", err))
+			return fmt.Errorf("cannot find rating: %w", err)
+		}
+		pbRating := s.toPbLaptopRating(laptop.GetId(), rating)
+		if pbRating.GetRatedCount() < req.GetMinRatedCount() || pbRating.GetWeightedScore() < req.GetMinWeightedScore() {
+			return nil
+		}
+		rated = append(rated, &pb.RatedLaptop{Laptop: laptop, Rating: pbRating})
+		return nil
+	})
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot search laptops: %v", err))
+	}
+
+	sort.Slice(rated, func(i, j int) bool {
+		rating1, rating2 := rated[i].GetRating(), rated[j].GetRating()
+		if rating1.GetWeightedScore() != rating2.GetWeightedScore() {
+			return rating1.GetWeightedScore() > rating2.GetWeightedScore()
+		}
+		if rating1.GetRatedCount() != rating2.GetRatedCount() {
+			return rating1.GetRatedCount() > rating2.GetRatedCount()
 		}
+		return rating1.GetLaptopId() < rating2.GetLaptopId()
+	})
+	if len(rated) > limit {
+		rated = rated[:limit]
 	}
-	return nil
+
+	res := &pb.ListTopRatedLaptopsResponse{
+		Laptops: rated,
+	}
+	return res, nil
+}
+
+// toPbLaptopRating converts the rating of the laptop, rating is nil if nobody rated it
+func (s *LaptopServer) toPbLaptopRating(laptopID string, rating *Rating) *pb.LaptopRating {
+	if rating == nil {
+		rating = &Rating{Histogram: make([]uint32, s.ratings.Scale.HistogramSize())}
+	}
+	return &pb.LaptopRating{
+		LaptopId:      laptopID,
+		RatedCount:    rating.Count,
+		AverageScore:  rating.Average(),
+		WeightedScore: s.ratings.Prior.WeightedScore(rating),
+		Histogram:     append([]uint32(nil), rating.Histogram...),
+	}
+}
+
+// filterOrAll returns the filter, or a filter matching every laptop if it is unset.
+// An unset pb.Filter only matches free laptops because of max_price_usd.
+func filterOrAll(filter *pb.Filter) *pb.Filter {
+	if filter == nil {
+		return &pb.Filter{MaxPriceUsd: math.Inf(1)}
+	}
+	return filter
+}
+
+// authenticatedUsername returns the user whose access token authorized the request
+func authenticatedUsername(ctx context.Context) (string, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims.Username == "" {
+		return "", status.Errorf(codes.Unauthenticated, "the request isn't made by an authenticated user")
+	}
+	return claims.Username, nil
 }
@@ -5,9 +5,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"grpc-go/pb"
 	"grpc-go/sample"
 	"grpc-go/service"
+	"math"
 	"testing"
 )
 
@@ -19,11 +21,21 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 	laptopInvalidID := sample.NewLaptop()
 	laptopInvalidID.Id = "invalid-uuid"
 
+	laptopNaNPrice := sample.NewLaptop()
+	laptopNaNPrice.PriceUsd = math.NaN()
+
 	laptopDuplicateID := sample.NewLaptop()
 	storeDuplicateID := service.NewInMemoryLaptopStore()
 	err := storeDuplicateID.Save(laptopDuplicateID)
 	require.Nil(t, err)
 
+	fileStore, err := service.NewFileLaptopStore(t.TempDir())
+	require.Nil(t, err)
+	t.Cleanup(func() { fileStore.Close() })
+
+	imageStore, err := service.NewDiskImageStore("img")
+	require.Nil(t, err)
+
 	testCase := []struct {
 		name        string
 		laptop      *pb.Laptop
@@ -36,7 +48,7 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 			name:        "success_with_id",
 			laptop:      sample.NewLaptop(),
 			store:       service.NewInMemoryLaptopStore(),
-			imageStore:  service.NewDiskImageStore("img"),
+			imageStore:  imageStore,
 			ratingStore: service.NewInMemoryRatingStore(),
 			code:        codes.OK,
 		},
@@ -44,7 +56,15 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 			name:        "success_no_id",
 			laptop:      laptopNoID,
 			store:       service.NewInMemoryLaptopStore(),
-			imageStore:  service.NewDiskImageStore("img"),
+			imageStore:  imageStore,
+			ratingStore: service.NewInMemoryRatingStore(),
+			code:        codes.OK,
+		},
+		{
+			name:        "success_file_store",
+			laptop:      sample.NewLaptop(),
+			store:       fileStore,
+			imageStore:  imageStore,
 			ratingStore: service.NewInMemoryRatingStore(),
 			code:        codes.OK,
 		},
@@ -52,7 +72,15 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 			name:        "failure_invalid_id",
 			laptop:      laptopInvalidID,
 			store:       service.NewInMemoryLaptopStore(),
-			imageStore:  service.NewDiskImageStore("img"),
+			imageStore:  imageStore,
+			ratingStore: service.NewInMemoryRatingStore(),
+			code:        codes.InvalidArgument,
+		},
+		{
+			name:        "failure_nan_price",
+			laptop:      laptopNaNPrice,
+			store:       service.NewInMemoryLaptopStore(),
+			imageStore:  imageStore,
 			ratingStore: service.NewInMemoryRatingStore(),
 			code:        codes.InvalidArgument,
 		},
@@ -60,7 +88,7 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 			name:        "failure_duplicate_id",
 			laptop:      laptopDuplicateID,
 			store:       storeDuplicateID,
-			imageStore:  service.NewDiskImageStore("img"),
+			imageStore:  imageStore,
 			ratingStore: service.NewInMemoryRatingStore(),
 			code:        codes.AlreadyExists,
 		},
@@ -91,3 +119,142 @@ func TestLaptopServer_CreateLaptop(t *testing.T) {
 		})
 	}
 }
+
+func TestLaptopServer_UpdateLaptop(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	laptop.PriceUsd = 2000
+	err := store.Save(laptop)
+	require.NoError(t, err)
+
+	server := service.NewLaptopServer(store, nil, nil)
+	update := &pb.Laptop{
+		Id:       laptop.Id,
+		Name:     "renamed",
+		PriceUsd: 1500,
+		Cpu:      &pb.CPU{MinGhz: 3.1},
+	}
+	req := &pb.UpdateLaptopRequest{
+		Laptop:          update,
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"price_usd", "cpu.min_ghz"}},
+		ExpectedVersion: 1,
+	}
+	res, err := server.UpdateLaptop(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), res.GetLaptop().GetVersion())
+	require.Equal(t, 1500.0, res.GetLaptop().GetPriceUsd())
+	require.Equal(t, 3.1, res.GetLaptop().GetCpu().GetMinGhz())
+	require.Equal(t, laptop.GetName(), res.GetLaptop().GetName())
+	require.Equal(t, laptop.GetCpu().GetNumberCores(), res.GetLaptop().GetCpu().GetNumberCores())
+
+	testCase := []struct {
+		name string
+		req  *pb.UpdateLaptopRequest
+		code codes.Code
+	}{
+		{
+			name: "stale_version",
+			req: &pb.UpdateLaptopRequest{
+				Laptop:          update,
+				UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				ExpectedVersion: 1,
+			},
+			code: codes.Aborted,
+		},
+		{
+			name: "not_found",
+			req: &pb.UpdateLaptopRequest{
+				Laptop: sample.NewLaptop(),
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "unknown_field",
+			req: &pb.UpdateLaptopRequest{
+				Laptop:     update,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"color"}},
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "immutable_field",
+			req: &pb.UpdateLaptopRequest{
+				Laptop:     update,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"version"}},
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "infinite_price",
+			req: &pb.UpdateLaptopRequest{
+				Laptop:     &pb.Laptop{Id: laptop.Id, PriceUsd: math.Inf(1)},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price_usd"}},
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := server.UpdateLaptop(context.Background(), tc.req)
+			require.Nil(t, res)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.code, st.Code())
+		})
+	}
+}
+
+func TestLaptopServer_DeleteLaptop(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	err := store.Save(laptop)
+	require.NoError(t, err)
+
+	server := service.NewLaptopServer(store, nil, nil)
+	_, err = server.DeleteLaptop(context.Background(), &pb.DeleteLaptopRequest{Id: laptop.Id, ExpectedVersion: 2})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = server.DeleteLaptop(context.Background(), &pb.DeleteLaptopRequest{Id: laptop.Id, ExpectedVersion: 1})
+	require.NoError(t, err)
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	_, err = server.DeleteLaptop(context.Background(), &pb.DeleteLaptopRequest{Id: laptop.Id})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLaptopServer_CollectImageGarbage(t *testing.T) {
+	t.Parallel()
+
+	imageStore, err := service.NewDiskImageStore(t.TempDir())
+	require.NoError(t, err)
+	laptopStore := service.NewInMemoryLaptopStore()
+	require.NoError(t, laptopStore.Save(sample.NewLaptop()))
+
+	// the laptop of the image is lost, like after a restart of a server keeping laptops in memory
+	writer, err := imageStore.Create(sample.NewLaptop().Id, "")
+	require.NoError(t, err)
+	_, err = writer.Write(newTestImage(t, "png", 40, 40))
+	require.NoError(t, err)
+	image, err := writer.Commit()
+	require.NoError(t, err)
+
+	server := service.NewLaptopServer(laptopStore, imageStore, nil)
+	_, err = server.CollectImageGarbage(context.Background(), &pb.CollectImageGarbageRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.FileExists(t, image.Path)
+
+	server.EnableImageGC()
+	res, err := server.CollectImageGarbage(context.Background(), &pb.CollectImageGarbageRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), res.GetDeletedImages())
+	require.NoFileExists(t, image.Path)
+}
@@ -3,27 +3,48 @@ package service
 import (
 	"context"
 	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"grpc-go/pb"
+	"grpc-go/units"
 	"log"
+	"strings"
 	"sync"
 )
 
-var ErrAlreadyExist = errors.New("record already exist")
+var (
+	ErrAlreadyExist    = errors.New("record already exist")
+	ErrNotFound        = errors.New("record not found")
+	ErrVersionMismatch = errors.New("record version mismatch")
+)
 
 type LaptopStore interface {
 	Save(laptop *pb.Laptop) error
 	Find(id string) (*pb.Laptop, error)
+	// Update replaces the stored laptop with the same id if its version equals expectedVersion
+	// (or expectedVersion is 0), and returns the stored laptop with a new version
+	Update(laptop *pb.Laptop, expectedVersion uint64) (*pb.Laptop, error)
+	// Delete removes the laptop if its version equals expectedVersion (or expectedVersion is 0)
+	Delete(id string, expectedVersion uint64) error
 	Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error
+	// Facets counts values and aggregates numeric fields of the laptops matching the filter
+	Facets(ctx context.Context, filter *pb.Filter, spec *pb.FacetSpec) (*pb.Facets, error)
 }
 
 type InMemoryLaptopStore struct {
-	mutex sync.RWMutex
-	data  map[string]*pb.Laptop
+	mutex   sync.RWMutex
+	data    map[string]*pb.Laptop
+	indexes []*laptopIndex
 }
 
 func NewInMemoryLaptopStore() LaptopStore {
+	return newInMemoryLaptopStore()
+}
+
+func newInMemoryLaptopStore() *InMemoryLaptopStore {
 	return &InMemoryLaptopStore{
-		data: make(map[string]*pb.Laptop, 0),
+		data:    make(map[string]*pb.Laptop, 0),
+		indexes: newLaptopIndexes(),
 	}
 }
 
@@ -33,8 +54,9 @@ func (m *InMemoryLaptopStore) Save(laptop *pb.Laptop) error {
 	if _, ok := m.data[laptop.Id]; ok {
 		return ErrAlreadyExist
 	}
+	laptop.Version = 1
 	other := laptop
-	m.data[other.Id] = other
+	m.store(other)
 	return nil
 }
 
@@ -47,30 +69,151 @@ func (m *InMemoryLaptopStore) Find(id string) (*pb.Laptop, error) {
 	return nil, nil
 }
 
+func (m *InMemoryLaptopStore) Update(laptop *pb.Laptop, expectedVersion uint64) (*pb.Laptop, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	current := m.data[laptop.Id]
+	err := checkVersion(current, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	other := newRevision(current, laptop)
+	m.store(other)
+	return other, nil
+}
+
+func (m *InMemoryLaptopStore) Delete(id string, expectedVersion uint64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	err := checkVersion(m.data[id], expectedVersion)
+	if err != nil {
+		return err
+	}
+	m.unstore(id)
+	return nil
+}
+
+// checkVersion verifies that the stored laptop exists and matches expectedVersion
+func checkVersion(current *pb.Laptop, expectedVersion uint64) error {
+	if current == nil {
+		return ErrNotFound
+	}
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// newRevision returns a copy of laptop that replaces current in the store
+func newRevision(current *pb.Laptop, laptop *pb.Laptop) *pb.Laptop {
+	other := proto.Clone(laptop).(*pb.Laptop)
+	other.Version = current.Version + 1
+	other.UpdatedAt = timestamppb.Now()
+	return other
+}
+
+// put stores the laptop, replacing any previous laptop with the same id
+func (m *InMemoryLaptopStore) put(laptop *pb.Laptop) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.store(laptop)
+	return nil
+}
+
+// remove deletes the laptop with the given id if it exists
+func (m *InMemoryLaptopStore) remove(id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.unstore(id)
+}
+
+// store puts the laptop in the map and the indexes, the caller must hold the write lock
+func (m *InMemoryLaptopStore) store(laptop *pb.Laptop) {
+	m.unstore(laptop.Id)
+	m.data[laptop.Id] = laptop
+	for _, index := range m.indexes {
+		index.addLaptop(laptop)
+	}
+}
+
+// unstore removes the laptop from the map and the indexes, the caller must hold the write lock
+func (m *InMemoryLaptopStore) unstore(id string) {
+	laptop, ok := m.data[id]
+	if !ok {
+		return
+	}
+	for _, index := range m.indexes {
+		index.removeLaptop(laptop)
+	}
+	delete(m.data, id)
+}
+
+// forEach calls fn for every laptop in the store regardless of any filter
+func (m *InMemoryLaptopStore) forEach(fn func(laptop *pb.Laptop) error) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, laptop := range m.data {
+		err := fn(laptop)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *InMemoryLaptopStore) Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
+	var err error
+	plan := planSearch(m.indexes, filter, len(m.data))
+	if plan == nil {
+		err = m.scan(ctx, filter, found)
+	} else {
+		err = plan.index.ascend(plan.from, plan.to, func(e indexEntry) error {
+			return m.check(ctx, filter, m.data[e.id], found)
+		})
+	}
+	if err == errSearchCancelled {
+		log.Println("context is cancelled")
+		return nil
+	}
+	return err
+}
+
+// scan checks every laptop against the filter, the caller must hold the read lock
+func (m *InMemoryLaptopStore) scan(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error {
 	for _, laptop := range m.data {
-		if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
-			log.Println("context is cancelled")
-			return nil
-		}
-		if isQualified(filter, laptop) {
-			err := found(laptop)
-			if err != nil {
-				return err
-			}
+		err := m.check(ctx, filter, laptop, found)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// errSearchCancelled stops a search without reporting an error to the caller
+var errSearchCancelled = errors.New("search is cancelled")
+
+func (m *InMemoryLaptopStore) check(ctx context.Context, filter *pb.Filter, laptop *pb.Laptop, found func(laptop *pb.Laptop) error) error {
+	if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
+		return errSearchCancelled
+	}
+	if isQualified(filter, laptop) {
+		return found(laptop)
+	}
+	return nil
+}
+
 func isQualified(filter *pb.Filter, laptop *pb.Laptop) bool {
 	if laptop.GetPriceUsd() > filter.GetMaxPriceUsd() {
 		return false
 	}
 
+	if laptop.GetPriceUsd() < filter.GetMinPriceUsd() {
+		return false
+	}
+
 	if laptop.GetCpu().GetNumberCores() < filter.GetMinCpuCores() {
 		return false
 	}
@@ -79,30 +222,121 @@ func isQualified(filter *pb.Filter, laptop *pb.Laptop) bool {
 		return false
 	}
 
-	if toBit(laptop.GetRam()) < toBit(filter.GetMinRam()) {
+	if units.ToBit(laptop.GetRam()) < units.ToBit(filter.GetMinRam()) {
+		return false
+	}
+
+	if len(filter.GetBrands()) > 0 && !containsFold(filter.GetBrands(), laptop.GetBrand()) {
+		return false
+	}
+
+	if len(filter.GetNames()) > 0 && !containsFold(filter.GetNames(), laptop.GetName()) {
+		return false
+	}
+
+	if !isGPUQualified(filter, laptop.GetGpus()) {
+		return false
+	}
+
+	if units.TotalStorage(laptop, pb.Storage_SSD) < units.ToBit(filter.GetMinSsd()) {
+		return false
+	}
+
+	if units.TotalStorage(laptop, pb.Storage_HDD) < units.ToBit(filter.GetMinHdd()) {
+		return false
+	}
+
+	if !isScreenQualified(filter, laptop.GetScreen()) {
+		return false
+	}
+
+	if len(filter.GetKeyboardLayouts()) > 0 && !containsLayout(filter.GetKeyboardLayouts(), laptop.GetKeyboard().GetLayout()) {
+		return false
+	}
+
+	if filter.Backlit != nil && laptop.GetKeyboard().GetBacklit() != filter.GetBacklit() {
+		return false
+	}
+
+	if filter.GetMaxWeightKg() > 0 && units.WeightKg(laptop) > filter.GetMaxWeightKg() {
+		return false
+	}
+
+	if laptop.GetReleaseYear() < filter.GetMinReleaseYear() {
+		return false
+	}
+
+	if filter.GetMaxReleaseYear() > 0 && laptop.GetReleaseYear() > filter.GetMaxReleaseYear() {
+		return false
+	}
+
+	return true
+}
+
+func isGPUQualified(filter *pb.Filter, gpus []*pb.GPU) bool {
+	if len(filter.GetGpuBrands()) == 0 && filter.GetMinGpuMemory() == nil {
+		return true
+	}
+	for _, gpu := range gpus {
+		if len(filter.GetGpuBrands()) > 0 && !containsFold(filter.GetGpuBrands(), gpu.GetBrand()) {
+			continue
+		}
+		if units.ToBit(gpu.GetMemory()) < units.ToBit(filter.GetMinGpuMemory()) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func isScreenQualified(filter *pb.Filter, screen *pb.Screen) bool {
+	if screen.GetSizeInch() < filter.GetMinScreenSizeInch() {
+		return false
+	}
+
+	if filter.GetMaxScreenSizeInch() > 0 && screen.GetSizeInch() > filter.GetMaxScreenSizeInch() {
+		return false
+	}
+
+	if screen.GetResolution().GetWidth() < filter.GetMinResolution().GetWidth() ||
+		screen.GetResolution().GetHeight() < filter.GetMinResolution().GetHeight() {
+		return false
+	}
+
+	if len(filter.GetPanels()) > 0 && !containsPanel(filter.GetPanels(), screen.GetPanel()) {
+		return false
+	}
+
+	if filter.Multitouch != nil && screen.GetMultitouch() != filter.GetMultitouch() {
 		return false
 	}
 
 	return true
 }
 
-func toBit(memory *pb.Memory) uint64 {
-	value := memory.GetValue()
-
-	switch memory.GetUnit() {
-	case pb.Memory_BIT:
-		return value
-	case pb.Memory_BYTE:
-		return value << 3 // 8 = 2^3
-	case pb.Memory_KILOBYTE:
-		return value << 13 // 1024 * 8 = 2^10 * 2^3 = 2^13
-	case pb.Memory_MEGABYTE:
-		return value << 23
-	case pb.Memory_GIGABYTE:
-		return value << 33
-	case pb.Memory_TERABYTE:
-		return value << 43
-	default:
-		return 0
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPanel(panels []pb.Screen_Panel, panel pb.Screen_Panel) bool {
+	for _, p := range panels {
+		if p == panel {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLayout(layouts []pb.Keyboard_Layout, layout pb.Keyboard_Layout) bool {
+	for _, l := range layouts {
+		if l == layout {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"grpc-go/pb"
+	"grpc-go/sample"
+	"grpc-go/service"
+	"testing"
+)
+
+func newFilterTestLaptop() *pb.Laptop {
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Dell"
+	laptop.Name = "XPS"
+	laptop.PriceUsd = 2000
+	laptop.Cpu.NumberCores = 4
+	laptop.Cpu.MinGhz = 2.5
+	laptop.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+	laptop.Gpus = []*pb.GPU{
+		{Brand: "Nvidia", Name: "RTX 2060", Memory: &pb.Memory{Value: 6, Unit: pb.Memory_GIGABYTE}},
+		{Brand: "AMD", Name: "RX 580", Memory: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}},
+	}
+	laptop.Storages = []*pb.Storage{
+		{Driver: pb.Storage_SSD, Memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+		{Driver: pb.Storage_SSD, Memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+		{Driver: pb.Storage_HDD, Memory: &pb.Memory{Value: 2, Unit: pb.Memory_TERABYTE}},
+	}
+	laptop.Screen = &pb.Screen{
+		SizeInch:   15.6,
+		Resolution: &pb.Screen_Resolution{Width: 3840, Height: 2160},
+		Panel:      pb.Screen_OLED,
+		Multitouch: true,
+	}
+	laptop.Keyboard = &pb.Keyboard{Layout: pb.Keyboard_QWERTY, Backlit: false}
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4.4}
+	laptop.ReleaseYear = 2018
+	return laptop
+}
+
+func TestInMemoryLaptopStore_SearchFilter(t *testing.T) {
+	t.Parallel()
+
+	gigabytes := func(value uint64) *pb.Memory {
+		return &pb.Memory{Value: value, Unit: pb.Memory_GIGABYTE}
+	}
+
+	testCase := []struct {
+		name    string
+		filter  *pb.Filter
+		matched bool
+	}{
+		{name: "no_criteria", filter: &pb.Filter{}, matched: true},
+		{name: "brand_match", filter: &pb.Filter{Brands: []string{"Lenovo", "dell"}}, matched: true},
+		{name: "brand_mismatch", filter: &pb.Filter{Brands: []string{"Apple"}}, matched: false},
+		{name: "name_match", filter: &pb.Filter{Names: []string{"XPS"}}, matched: true},
+		{name: "name_mismatch", filter: &pb.Filter{Names: []string{"Vostro", "Latitude"}}, matched: false},
+		{name: "min_price_match", filter: &pb.Filter{MinPriceUsd: 2000}, matched: true},
+		{name: "min_price_mismatch", filter: &pb.Filter{MinPriceUsd: 2000.01}, matched: false},
+		{name: "gpu_brand_match", filter: &pb.Filter{GpuBrands: []string{"AMD"}}, matched: true},
+		{name: "gpu_brand_mismatch", filter: &pb.Filter{GpuBrands: []string{"Intel"}}, matched: false},
+		{name: "gpu_memory_match", filter: &pb.Filter{MinGpuMemory: gigabytes(6)}, matched: true},
+		{name: "gpu_memory_mismatch", filter: &pb.Filter{MinGpuMemory: gigabytes(8)}, matched: false},
+		{name: "gpu_brand_memory_same_gpu", filter: &pb.Filter{GpuBrands: []string{"AMD"}, MinGpuMemory: gigabytes(6)}, matched: false},
+		{name: "ssd_total_match", filter: &pb.Filter{MinSsd: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}}, matched: true},
+		{name: "ssd_total_mismatch", filter: &pb.Filter{MinSsd: gigabytes(1025)}, matched: false},
+		{name: "hdd_total_match", filter: &pb.Filter{MinHdd: gigabytes(2048)}, matched: true},
+		{name: "hdd_total_mismatch", filter: &pb.Filter{MinHdd: &pb.Memory{Value: 3, Unit: pb.Memory_TERABYTE}}, matched: false},
+		{name: "screen_size_match", filter: &pb.Filter{MinScreenSizeInch: 15, MaxScreenSizeInch: 16}, matched: true},
+		{name: "screen_size_too_small", filter: &pb.Filter{MinScreenSizeInch: 17}, matched: false},
+		{name: "screen_size_too_large", filter: &pb.Filter{MaxScreenSizeInch: 14}, matched: false},
+		{name: "resolution_match", filter: &pb.Filter{MinResolution: &pb.Screen_Resolution{Width: 1920, Height: 1080}}, matched: true},
+		{name: "resolution_mismatch", filter: &pb.Filter{MinResolution: &pb.Screen_Resolution{Width: 1920, Height: 2400}}, matched: false},
+		{name: "panel_match", filter: &pb.Filter{Panels: []pb.Screen_Panel{pb.Screen_IPS, pb.Screen_OLED}}, matched: true},
+		{name: "panel_mismatch", filter: &pb.Filter{Panels: []pb.Screen_Panel{pb.Screen_IPS}}, matched: false},
+		{name: "multitouch_match", filter: &pb.Filter{Multitouch: proto.Bool(true)}, matched: true},
+		{name: "multitouch_mismatch", filter: &pb.Filter{Multitouch: proto.Bool(false)}, matched: false},
+		{name: "keyboard_layout_match", filter: &pb.Filter{KeyboardLayouts: []pb.Keyboard_Layout{pb.Keyboard_QWERTY}}, matched: true},
+		{name: "keyboard_layout_mismatch", filter: &pb.Filter{KeyboardLayouts: []pb.Keyboard_Layout{pb.Keyboard_AZERTY}}, matched: false},
+		{name: "backlit_match", filter: &pb.Filter{Backlit: proto.Bool(false)}, matched: true},
+		{name: "backlit_mismatch", filter: &pb.Filter{Backlit: proto.Bool(true)}, matched: false},
+		{name: "weight_lb_converted_match", filter: &pb.Filter{MaxWeightKg: 2.0}, matched: true},
+		{name: "weight_lb_converted_mismatch", filter: &pb.Filter{MaxWeightKg: 1.9}, matched: false},
+		{name: "release_year_match", filter: &pb.Filter{MinReleaseYear: 2018, MaxReleaseYear: 2018}, matched: true},
+		{name: "release_year_too_old", filter: &pb.Filter{MinReleaseYear: 2019}, matched: false},
+		{name: "release_year_too_new", filter: &pb.Filter{MaxReleaseYear: 2017}, matched: false},
+	}
+
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := service.NewInMemoryLaptopStore()
+			laptop := newFilterTestLaptop()
+			require.NoError(t, store.Save(laptop))
+
+			tc.filter.MaxPriceUsd = 3000
+			found := false
+			err := store.Search(context.Background(), tc.filter, func(other *pb.Laptop) error {
+				found = other.Id == laptop.Id
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.matched, found)
+		})
+	}
+}
+
+func TestInMemoryLaptopStore_SearchUnsetMaxPrice(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop))
+
+	// max_price_usd applies even if it is unset, so only free laptops match
+	for _, filter := range []*pb.Filter{nil, {}, {Brands: []string{laptop.GetBrand()}}} {
+		found := false
+		err := store.Search(context.Background(), filter, func(other *pb.Laptop) error {
+			found = true
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, found)
+	}
+}
+
+func TestInMemoryLaptopStore_Facets(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	prices := []float64{1200, 1450, 1600, 2900}
+	brands := []string{"Dell", "Dell", "Apple", "Lenovo"}
+	for i := range prices {
+		laptop := sample.NewLaptop()
+		laptop.Brand = brands[i]
+		laptop.PriceUsd = prices[i]
+		laptop.ReleaseYear = float64(2016 + i)
+		require.NoError(t, store.Save(laptop))
+	}
+
+	spec := &pb.FacetSpec{
+		ValueFields:   []string{"brand", "price_usd"},
+		NumericFields: []string{"price_usd", "release_year"},
+	}
+	facets, err := store.Facets(context.Background(), &pb.Filter{MaxPriceUsd: 2000}, spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), facets.GetTotal())
+
+	require.Len(t, facets.GetValueFacets(), 2)
+	brandCounts := facets.GetValueFacets()[0].GetCounts()
+	require.Equal(t, "Dell", brandCounts[0].GetValue())
+	require.Equal(t, uint64(2), brandCounts[0].GetCount())
+	require.Equal(t, "Apple", brandCounts[1].GetValue())
+	priceCounts := facets.GetValueFacets()[1].GetCounts()
+	require.Equal(t, "1000-1500", priceCounts[0].GetValue())
+	require.Equal(t, uint64(2), priceCounts[0].GetCount())
+	require.Equal(t, "1500-2000", priceCounts[1].GetValue())
+
+	price := facets.GetNumericFacets()[0]
+	require.Equal(t, uint64(3), price.GetCount())
+	require.Equal(t, 1200.0, price.GetMin())
+	require.Equal(t, 1600.0, price.GetMax())
+	require.InDelta(t, 1416.67, price.GetAvg(), 0.01)
+	year := facets.GetNumericFacets()[1]
+	require.Equal(t, 2016.0, year.GetMin())
+	require.Equal(t, 2018.0, year.GetMax())
+
+	_, err = store.Facets(context.Background(), nil, &pb.FacetSpec{ValueFields: []string{"color"}})
+	require.ErrorIs(t, err, service.ErrUnknownFacetField)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"grpc-go/pb"
+	"sync"
+)
+
+const (
+	// defaultWatchHistorySize is the number of past events kept to resume a watch
+	defaultWatchHistorySize = 4096
+	// watchBufferSize is the number of events a slow watcher may lag behind before it is dropped
+	watchBufferSize = 256
+)
+
+var ErrRevisionUnavailable = errors.New("revision is no longer available")
+
+// LaptopWatcher is implemented by laptop stores that publish their changes
+type LaptopWatcher interface {
+	// Subscribe returns the retained events after startRevision and a subscription for the next ones.
+	// A startRevision of 0 only subscribes to new events, otherwise startEpoch must be the current epoch.
+	Subscribe(startEpoch string, startRevision uint64) ([]*LaptopChange, *LaptopSubscription, error)
+	Unsubscribe(subscription *LaptopSubscription)
+}
+
+// LaptopChange is an event together with the laptop as it was before the change
+type LaptopChange struct {
+	Event    *pb.LaptopEvent
+	Previous *pb.Laptop
+}
+
+// LaptopSubscription delivers the changes published after it was created.
+// Its channel is closed if the subscriber doesn't keep up.
+type LaptopSubscription struct {
+	Changes <-chan *LaptopChange
+	changes chan *LaptopChange
+}
+
+// LaptopBroadcaster assigns revisions to laptop changes and fans them out to the subscribers.
+// The revisions are kept in memory, the random epoch tells them apart from those of a previous instance.
+type LaptopBroadcaster struct {
+	mutex       sync.Mutex
+	epoch       string
+	revision    uint64
+	history     []*LaptopChange
+	historySize int
+	subscribers map[*LaptopSubscription]bool
+}
+
+func NewLaptopBroadcaster(historySize int) *LaptopBroadcaster {
+	return &LaptopBroadcaster{
+		epoch:       uuid.New().String(),
+		historySize: historySize,
+		subscribers: make(map[*LaptopSubscription]bool),
+	}
+}
+
+func (b *LaptopBroadcaster) Publish(eventType pb.LaptopEvent_Type, laptop *pb.Laptop, previous *pb.Laptop) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.revision++
+	change := &LaptopChange{
+		Event: &pb.LaptopEvent{
+			Type:     eventType,
+			Revision: b.revision,
+			Laptop:   laptop,
+			Epoch:    b.epoch,
+		},
+		Previous: previous,
+	}
+
+	b.history = append(b.history, change)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for subscription := range b.subscribers {
+		select {
+		case subscription.changes <- change:
+		default:
+			// the subscriber fell behind, it can resume from its last revision
+			close(subscription.changes)
+			delete(b.subscribers, subscription)
+		}
+	}
+}
+
+func (b *LaptopBroadcaster) Subscribe(startEpoch string, startRevision uint64) ([]*LaptopChange, *LaptopSubscription, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var backlog []*LaptopChange
+	if startRevision > 0 {
+		if startEpoch != b.epoch {
+			// revisions restart after the server restarts
+			return nil, nil, fmt.Errorf("%w: epoch %q is not the current epoch %q", ErrRevisionUnavailable, startEpoch, b.epoch)
+		}
+		if startRevision > b.revision {
+			return nil, nil, fmt.Errorf("%w: revision %d is ahead of the current revision %d", ErrRevisionUnavailable, startRevision, b.revision)
+		}
+		oldest := b.revision - uint64(len(b.history)) + 1
+		if startRevision+1 < oldest {
+			return nil, nil, fmt.Errorf("%w: oldest retained revision is %d", ErrRevisionUnavailable, oldest)
+		}
+		backlog = append(backlog, b.history[startRevision+1-oldest:]...)
+	}
+
+	changes := make(chan *LaptopChange, watchBufferSize)
+	subscription := &LaptopSubscription{
+		Changes: changes,
+		changes: changes,
+	}
+	b.subscribers[subscription] = true
+	return backlog, subscription, nil
+}
+
+func (b *LaptopBroadcaster) Unsubscribe(subscription *LaptopSubscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.subscribers[subscription] {
+		close(subscription.changes)
+		delete(b.subscribers, subscription)
+	}
+}
+
+// WatchableLaptopStore publishes every change of the wrapped store to a LaptopBroadcaster.
+// Writes are serialized, so the revisions follow the order in which the changes were applied.
+type WatchableLaptopStore struct {
+	LaptopStore
+	mutex       sync.Mutex
+	broadcaster *LaptopBroadcaster
+}
+
+func NewWatchableLaptopStore(store LaptopStore) *WatchableLaptopStore {
+	return &WatchableLaptopStore{
+		LaptopStore: store,
+		broadcaster: NewLaptopBroadcaster(defaultWatchHistorySize),
+	}
+}
+
+func (s *WatchableLaptopStore) Save(laptop *pb.Laptop) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.LaptopStore.Save(laptop)
+	if err != nil {
+		return err
+	}
+	s.broadcaster.Publish(pb.LaptopEvent_CREATED, laptop, nil)
+	return nil
+}
+
+func (s *WatchableLaptopStore) Update(laptop *pb.Laptop, expectedVersion uint64) (*pb.Laptop, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, err := s.LaptopStore.Find(laptop.Id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.LaptopStore.Update(laptop, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcaster.Publish(pb.LaptopEvent_UPDATED, updated, previous)
+	return updated, nil
+}
+
+func (s *WatchableLaptopStore) Delete(id string, expectedVersion uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, err := s.LaptopStore.Find(id)
+	if err != nil {
+		return err
+	}
+	err = s.LaptopStore.Delete(id, expectedVersion)
+	if err != nil {
+		return err
+	}
+	s.broadcaster.Publish(pb.LaptopEvent_DELETED, previous, previous)
+	return nil
+}
+
+func (s *WatchableLaptopStore) Subscribe(startEpoch string, startRevision uint64) ([]*LaptopChange, *LaptopSubscription, error) {
+	return s.broadcaster.Subscribe(startEpoch, startRevision)
+}
+
+func (s *WatchableLaptopStore) Unsubscribe(subscription *LaptopSubscription) {
+	s.broadcaster.Unsubscribe(subscription)
+}
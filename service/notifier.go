@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notification is a message delivered to a user out of band, such as a password reset code
+type Notification struct {
+	Username string    `json:"username"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// Notifier delivers notifications to the users, an implementation may send emails or text messages
+type Notifier interface {
+	Notify(notification *Notification) error
+}
+
+// LogNotifier writes the notifications to the server log, it is only meant for local development
+type LogNotifier struct{}
+
+func NewLogNotifier() Notifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(notification *Notification) error {
+	log.Printf("notify %s: %s: %s", notification.Username, notification.Subject, notification.Body)
+	return nil
+}
+
+// FileNotifier appends the notifications to a file, one JSON object per line
+type FileNotifier struct {
+	mutex sync.Mutex
+	path  string
+}
+
+func NewFileNotifier(path string) Notifier {
+	return &FileNotifier{
+		path: path,
+	}
+}
+
+func (n *FileNotifier) Notify(notification *Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("cannot marshal notification: %w", err)
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open notification file: %w", err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write notification file: %w", err)
+	}
+	return file.Close()
+}
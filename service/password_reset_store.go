@@ -0,0 +1,101 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+)
+
+// passwordResetPruneInterval is how often the expired reset codes are removed from the store
+const passwordResetPruneInterval = time.Minute
+
+var (
+	ErrInvalidResetCode     = errors.New("reset code is invalid or expired")
+	ErrTooManyResetAttempts = errors.New("too many wrong reset codes")
+)
+
+// PasswordReset is a pending password reset of a user, only the hash of the code is kept
+type PasswordReset struct {
+	Username  string
+	CodeHash  string
+	ExpiresAt time.Time
+	// Attempts counts the wrong codes presented for the reset
+	Attempts int
+}
+
+type PasswordResetStore interface {
+	// Save replaces the code of the pending reset of the user. The wrong codes counted for an unexpired reset
+	// are kept, and it returns ErrTooManyResetAttempts if they reached the limit, until that reset expires.
+	Save(reset *PasswordReset) error
+	// Verify consumes the pending reset of the user if the code hash matches, and counts a wrong code otherwise.
+	// It returns ErrInvalidResetCode if there is no pending reset, it expired, or the code is wrong,
+	// and ErrTooManyResetAttempts once the wrong codes reached the limit.
+	Verify(username string, codeHash string) error
+}
+
+type InMemoryPasswordResetStore struct {
+	mutex       sync.Mutex
+	maxAttempts int
+	resets      map[string]*PasswordReset
+	lastPrune   time.Time
+}
+
+func NewInMemoryPasswordResetStore() PasswordResetStore {
+	return &InMemoryPasswordResetStore{
+		maxAttempts: maxPasswordResetAttempts,
+		resets:      make(map[string]*PasswordReset),
+	}
+}
+
+func (m *InMemoryPasswordResetStore) Save(reset *PasswordReset) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+	other := *reset
+	if previous := m.resets[reset.Username]; previous != nil && now.Before(previous.ExpiresAt) {
+		// otherwise requesting new codes would allow to guess without limit
+		if previous.Attempts >= m.maxAttempts {
+			return ErrTooManyResetAttempts
+		}
+		other.Attempts = previous.Attempts
+	}
+	m.resets[reset.Username] = &other
+	return nil
+}
+
+func (m *InMemoryPasswordResetStore) Verify(username string, codeHash string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	reset := m.resets[username]
+	if reset == nil || time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetCode
+	}
+	if reset.Attempts >= m.maxAttempts {
+		// the reset is kept until it expires, so no new code can be requested before
+		return ErrTooManyResetAttempts
+	}
+	if subtle.ConstantTimeCompare([]byte(reset.CodeHash), []byte(codeHash)) != 1 {
+		reset.Attempts++
+		return ErrInvalidResetCode
+	}
+	delete(m.resets, username)
+	return nil
+}
+
+// prune removes the expired resets
+func (m *InMemoryPasswordResetStore) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < passwordResetPruneInterval {
+		return
+	}
+	m.lastPrune = now
+
+	for username, reset := range m.resets {
+		if now.After(reset.ExpiresAt) {
+			delete(m.resets, username)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"grpc-go/service"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemoryPasswordResetStore_ConcurrentAttempts(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryPasswordResetStore()
+	require.NoError(t, store.Save(&service.PasswordReset{
+		Username:  "alice",
+		CodeHash:  "right",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	// only the allowed number of wrong codes are checked, however many arrive at once
+	errs := make([]error, 50)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.Verify("alice", "wrong")
+		}(i)
+	}
+	wg.Wait()
+
+	checked := 0
+	for _, err := range errs {
+		if errors.Is(err, service.ErrInvalidResetCode) {
+			checked++
+		} else {
+			require.ErrorIs(t, err, service.ErrTooManyResetAttempts)
+		}
+	}
+	require.Equal(t, 5, checked)
+
+	require.ErrorIs(t, store.Verify("alice", "right"), service.ErrTooManyResetAttempts)
+	err := store.Save(&service.PasswordReset{
+		Username:  "alice",
+		CodeHash:  "other",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, service.ErrTooManyResetAttempts)
+}
+
+func TestInMemoryPasswordResetStore_Verify(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryPasswordResetStore()
+	require.ErrorIs(t, store.Verify("alice", "right"), service.ErrInvalidResetCode)
+
+	require.NoError(t, store.Save(&service.PasswordReset{
+		Username:  "alice",
+		CodeHash:  "right",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	require.ErrorIs(t, store.Verify("alice", "right"), service.ErrInvalidResetCode)
+
+	// an expired reset doesn't pass its wrong codes on
+	require.NoError(t, store.Save(&service.PasswordReset{
+		Username:  "alice",
+		CodeHash:  "right",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, store.Verify("alice", "right"))
+
+	// the code can only be used once
+	require.ErrorIs(t, store.Verify("alice", "right"), service.ErrInvalidResetCode)
+}
@@ -1,41 +1,241 @@
 package service
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// maxRatingHistogramSize limits the number of whole scores of a rating scale
+const maxRatingHistogramSize = 1000
+
+// ErrInvalidScore is returned for a score that isn't on the rating scale
+var ErrInvalidScore = errors.New("invalid score")
+
+// RatingScale is the set of scores users can give: from Min to Max in multiples of Step,
+// every score between Min and Max is valid if Step is 0
+type RatingScale struct {
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// DefaultRatingScale rates laptops from 1 to 10 in half points
+var DefaultRatingScale = RatingScale{Min: 1, Max: 10, Step: 0.5}
+
+// Check returns an error if the scale has no scores or too many whole scores for a histogram
+func (scale RatingScale) Check() error {
+	if math.IsNaN(scale.Min) || math.IsInf(scale.Min, 0) || math.IsNaN(scale.Max) || math.IsInf(scale.Max, 0) {
+		return fmt.Errorf("rating scale [%v, %v] is not finite", scale.Min, scale.Max)
+	}
+	if scale.Min >= scale.Max {
+		return fmt.Errorf("lowest score %v is not below the highest score %v", scale.Min, scale.Max)
+	}
+	if !(scale.Step >= 0) || math.IsInf(scale.Step, 0) || scale.Step > scale.Max-scale.Min {
+		return fmt.Errorf("step %v is not between 0 and the range of the scale", scale.Step)
+	}
+	// the range is compared as a float, a huge range would overflow the int size of the histogram
+	if math.Round(scale.Max)-math.Round(scale.Min) >= maxRatingHistogramSize {
+		return fmt.Errorf("rating scale [%v, %v] has more than %d whole scores", scale.Min, scale.Max, maxRatingHistogramSize)
+	}
+	return nil
+}
+
+// Middle returns the score halfway between the lowest and the highest score
+func (scale RatingScale) Middle() float64 {
+	return (scale.Min + scale.Max) / 2
+}
+
+// HistogramSize returns the number of buckets of a histogram, one for each whole score of the scale
+func (scale RatingScale) HistogramSize() int {
+	return int(math.Round(scale.Max)-math.Round(scale.Min)) + 1
+}
+
+// histogramBucket returns the bucket of the histogram the score is counted in
+func (scale RatingScale) histogramBucket(score float64) int {
+	bucket := int(math.Round(score) - math.Round(scale.Min))
+	if bucket < 0 {
+		return 0
+	}
+	if bucket >= scale.HistogramSize() {
+		return scale.HistogramSize() - 1
+	}
+	return bucket
+}
+
+// Validate returns an error wrapping ErrInvalidScore if the score isn't on the scale
+func (scale RatingScale) Validate(score float64) error {
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return fmt.Errorf("%w: %v is not a number", ErrInvalidScore, score)
+	}
+	if score < scale.Min || score > scale.Max {
+		return fmt.Errorf("%w: %v is out of the range [%v, %v]", ErrInvalidScore, score, scale.Min, scale.Max)
+	}
+	if scale.Step > 0 {
+		steps := (score - scale.Min) / scale.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			return fmt.Errorf("%w: %v is not a multiple of %v from %v", ErrInvalidScore, score, scale.Step, scale.Min)
+		}
+	}
+	return nil
+}
 
 type RateStore interface {
-	Add(laptopId string, score float64) (*Rating, error)
+	// Add saves the score the user gave to the laptop, replacing the previous score of the user
+	Add(laptopId string, username string, score float64) (*Rating, error)
+	// Remove deletes the score the user gave to the laptop, it returns ErrNotFound if the user didn't rate it
+	Remove(laptopId string, username string) (*Rating, error)
+	Find(laptopId string) (*Rating, error)
 }
 
 type Rating struct {
 	Count uint32
 	Sum   float64
+	// Histogram counts the ratings of each score rounded to a whole number,
+	// Histogram[0] counts the lowest score of the scale rounded to a whole number
+	Histogram []uint32
+}
+
+func (rating *Rating) clone() *Rating {
+	other := *rating
+	other.Histogram = append([]uint32(nil), rating.Histogram...)
+	return &other
+}
+
+// Average returns the mean score of the laptop, 0 if nobody rated it
+func (rating *Rating) Average() float64 {
+	if rating.Count == 0 {
+		return 0
+	}
+	return rating.Sum / float64(rating.Count)
+}
+
+// RatingPrior is what is assumed about a laptop before anyone rated it.
+// The weighted score of a laptop counts Weight ratings of Mean on top of its own ratings.
+type RatingPrior struct {
+	Mean   float64
+	Weight float64
+}
+
+// DefaultRatingPrior pulls the laptops with few ratings towards the middle of the score range
+var DefaultRatingPrior = RatingPrior{Mean: DefaultRatingScale.Middle(), Weight: 10}
+
+// RatingConfig is how users rate laptops and how the laptops are ranked
+type RatingConfig struct {
+	Scale RatingScale
+	Prior RatingPrior
+}
+
+// Check returns an error if the scale is invalid or the prior mean isn't a score of the scale
+func (config RatingConfig) Check() error {
+	err := config.Scale.Check()
+	if err != nil {
+		return err
+	}
+	if !(config.Prior.Mean >= config.Scale.Min && config.Prior.Mean <= config.Scale.Max) {
+		return fmt.Errorf("prior mean %v is out of the range [%v, %v]", config.Prior.Mean, config.Scale.Min, config.Scale.Max)
+	}
+	if !(config.Prior.Weight >= 0) || math.IsInf(config.Prior.Weight, 0) {
+		return fmt.Errorf("prior weight %v is not a non-negative number", config.Prior.Weight)
+	}
+	return nil
+}
+
+var DefaultRatingConfig = RatingConfig{Scale: DefaultRatingScale, Prior: DefaultRatingPrior}
+
+// WeightedScore returns the Bayesian average of the rating, rating can be nil for a laptop nobody rated
+func (prior RatingPrior) WeightedScore(rating *Rating) float64 {
+	weight, sum := prior.Weight, prior.Weight*prior.Mean
+	if rating != nil {
+		weight += float64(rating.Count)
+		sum += rating.Sum
+	}
+	if weight == 0 {
+		return 0
+	}
+	return sum / weight
 }
 
 type InMemoryRatingStore struct {
 	mutex  sync.RWMutex
+	scale  RatingScale
 	rating map[string]*Rating
+	// scores maps a laptop id to the score of each user who rated it
+	scores map[string]map[string]float64
 }
 
 func NewInMemoryRatingStore() RateStore {
+	return NewInMemoryRatingStoreWithScale(DefaultRatingScale)
+}
+
+// NewInMemoryRatingStoreWithScale returns a store whose histograms have a bucket for each whole score of the scale
+func NewInMemoryRatingStoreWithScale(scale RatingScale) RateStore {
 	return &InMemoryRatingStore{
+		scale:  scale,
 		rating: make(map[string]*Rating),
+		scores: make(map[string]map[string]float64),
 	}
 }
 
-func (m *InMemoryRatingStore) Add(laptopId string, score float64) (*Rating, error) {
+func (m *InMemoryRatingStore) Add(laptopId string, username string, score float64) (*Rating, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	scores := m.scores[laptopId]
+	if scores == nil {
+		scores = make(map[string]float64)
+		m.scores[laptopId] = scores
+	}
 	rating := m.rating[laptopId]
 	if rating == nil {
-		rating = &Rating{
-			Count: 1,
-			Sum:   score,
-		}
+		rating = &Rating{Histogram: make([]uint32, m.scale.HistogramSize())}
+		m.rating[laptopId] = rating
+	}
+
+	previous, rated := scores[username]
+	if rated {
+		rating.Sum += score - previous
+		rating.Histogram[m.scale.histogramBucket(previous)]--
 	} else {
 		rating.Count++
 		rating.Sum += score
 	}
-	m.rating[laptopId] = rating
-	return rating, nil
+	rating.Histogram[m.scale.histogramBucket(score)]++
+	scores[username] = score
+	return rating.clone(), nil
+}
+
+func (m *InMemoryRatingStore) Remove(laptopId string, username string) (*Rating, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	scores := m.scores[laptopId]
+	score, rated := scores[username]
+	if !rated {
+		return nil, ErrNotFound
+	}
+
+	delete(scores, username)
+	rating := m.rating[laptopId]
+	rating.Count--
+	rating.Sum -= score
+	rating.Histogram[m.scale.histogramBucket(score)]--
+	if rating.Count == 0 {
+		delete(m.scores, laptopId)
+		delete(m.rating, laptopId)
+		return &Rating{Histogram: make([]uint32, m.scale.HistogramSize())}, nil
+	}
+	return rating.clone(), nil
+}
+
+func (m *InMemoryRatingStore) Find(laptopId string) (*Rating, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	rating := m.rating[laptopId]
+	if rating == nil {
+		return nil, nil
+	}
+	return rating.clone(), nil
 }
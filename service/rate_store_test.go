@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"grpc-go/service"
+	"math"
+	"testing"
+)
+
+func TestRatingConfig_Check(t *testing.T) {
+	t.Parallel()
+
+	testCase := []struct {
+		name   string
+		config service.RatingConfig
+		valid  bool
+	}{
+		{name: "default", config: service.DefaultRatingConfig, valid: true},
+		{name: "any_score", config: service.RatingConfig{Scale: service.RatingScale{Min: 0, Max: 1}, Prior: service.RatingPrior{Mean: 0.5}}, valid: true},
+		{name: "empty_range", config: service.RatingConfig{Scale: service.RatingScale{Min: 5, Max: 5}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "inverted_range", config: service.RatingConfig{Scale: service.RatingScale{Min: 10, Max: 1}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "negative_step", config: service.RatingConfig{Scale: service.RatingScale{Min: 1, Max: 10, Step: -1}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "infinite_max", config: service.RatingConfig{Scale: service.RatingScale{Min: 1, Max: math.Inf(1)}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "nan_min", config: service.RatingConfig{Scale: service.RatingScale{Min: math.NaN(), Max: 10}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "infinite_min", config: service.RatingConfig{Scale: service.RatingScale{Min: math.Inf(-1), Max: 10}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "max_scores", config: service.RatingConfig{Scale: service.RatingScale{Min: 1, Max: 1000}, Prior: service.RatingPrior{Mean: 5}}, valid: true},
+		{name: "huge_max", config: service.RatingConfig{Scale: service.RatingScale{Min: 1, Max: 1e19}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "huge_range", config: service.RatingConfig{Scale: service.RatingScale{Min: -math.MaxFloat64, Max: math.MaxFloat64}, Prior: service.RatingPrior{Mean: 0}}},
+		{name: "too_many_scores", config: service.RatingConfig{Scale: service.RatingScale{Min: 0, Max: 1e9}, Prior: service.RatingPrior{Mean: 5}}},
+		{name: "prior_mean_out_of_range", config: service.RatingConfig{Scale: service.DefaultRatingScale, Prior: service.RatingPrior{Mean: 0, Weight: 10}}},
+		{name: "negative_prior_weight", config: service.RatingConfig{Scale: service.DefaultRatingScale, Prior: service.RatingPrior{Mean: 5, Weight: -1}}},
+	}
+
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.config.Check()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestInMemoryRatingStore_Histogram(t *testing.T) {
+	t.Parallel()
+
+	scale := service.RatingScale{Min: 0, Max: 5, Step: 1}
+	require.Equal(t, 6, scale.HistogramSize())
+	require.Equal(t, 2.5, scale.Middle())
+	require.Equal(t, 5.5, service.DefaultRatingPrior.Mean)
+
+	store := service.NewInMemoryRatingStoreWithScale(scale)
+	_, err := store.Add("laptop", "alice", 0)
+	require.NoError(t, err)
+	rating, err := store.Add("laptop", "bob", 5)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1, 0, 0, 0, 0, 1}, rating.Histogram)
+
+	// the returned rating is a copy
+	rating.Histogram[0] = 7
+	rating, err = store.Find("laptop")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1, 0, 0, 0, 0, 1}, rating.Histogram)
+
+	rating, err = store.Remove("laptop", "alice")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0, 0, 0, 0, 0, 1}, rating.Histogram)
+}
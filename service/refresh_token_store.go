@@ -0,0 +1,184 @@
+package service
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// maxUsedRefreshTokens is the number of used tokens a family keeps to detect their reuse
+const maxUsedRefreshTokens = 16
+
+// RefreshToken is the stored form of a refresh token. Only the hash of the token is kept,
+// the tokens rotated from the same login form a family.
+type RefreshToken struct {
+	Hash      string
+	FamilyId  string
+	Username  string
+	ExpiresAt time.Time
+	// Used is set once the token was exchanged for a new one, presenting it again means it was stolen
+	Used bool
+}
+
+type RefreshTokenStore interface {
+	Save(token *RefreshToken) error
+	// Find returns the token without marking it as used, it returns ErrNotFound if the token doesn't exist
+	Find(hash string) (*RefreshToken, error)
+	// Use marks the token as used and returns it as it was before, it returns ErrNotFound if the token doesn't exist
+	Use(hash string) (*RefreshToken, error)
+	// RevokeFamily deletes every token of the family
+	RevokeFamily(familyId string) error
+	// RevokeUser deletes every token of the user
+	RevokeUser(username string) error
+}
+
+type InMemoryRefreshTokenStore struct {
+	mutex    sync.Mutex
+	tokens   map[string]*RefreshToken
+	families map[string]*refreshTokenFamily
+	// expiries orders the tokens by expiry, so a prune only visits the expired tokens
+	expiries refreshTokenExpiries
+}
+
+// refreshTokenFamily is the tokens rotated from one login
+type refreshTokenFamily struct {
+	username string
+	// hashes are the used tokens from the oldest, followed by the current token
+	hashes []string
+}
+
+func NewInMemoryRefreshTokenStore() RefreshTokenStore {
+	return &InMemoryRefreshTokenStore{
+		tokens:   make(map[string]*RefreshToken),
+		families: make(map[string]*refreshTokenFamily),
+	}
+}
+
+func (m *InMemoryRefreshTokenStore) Save(token *RefreshToken) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.tokens[token.Hash] != nil {
+		return ErrAlreadyExist
+	}
+	m.prune(time.Now())
+	other := *token
+	m.tokens[token.Hash] = &other
+	heap.Push(&m.expiries, refreshTokenExpiry{hash: token.Hash, expiresAt: token.ExpiresAt})
+
+	family := m.families[token.FamilyId]
+	if family == nil {
+		family = &refreshTokenFamily{username: token.Username}
+		m.families[token.FamilyId] = family
+	}
+	family.hashes = append(family.hashes, token.Hash)
+	// an older used token is forgotten, presenting it is rejected without revoking the family
+	if len(family.hashes) > maxUsedRefreshTokens+1 {
+		delete(m.tokens, family.hashes[0])
+		family.hashes = family.hashes[1:]
+	}
+	return nil
+}
+
+func (m *InMemoryRefreshTokenStore) Find(hash string) (*RefreshToken, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	token := m.tokens[hash]
+	if token == nil {
+		return nil, ErrNotFound
+	}
+	other := *token
+	return &other, nil
+}
+
+func (m *InMemoryRefreshTokenStore) Use(hash string) (*RefreshToken, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	token := m.tokens[hash]
+	if token == nil {
+		return nil, ErrNotFound
+	}
+	other := *token
+	token.Used = true
+	return &other, nil
+}
+
+func (m *InMemoryRefreshTokenStore) RevokeFamily(familyId string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.deleteFamily(familyId)
+	return nil
+}
+
+func (m *InMemoryRefreshTokenStore) RevokeUser(username string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for familyId, family := range m.families {
+		if family.username == username {
+			m.deleteFamily(familyId)
+		}
+	}
+	return nil
+}
+
+func (m *InMemoryRefreshTokenStore) deleteFamily(familyId string) {
+	family := m.families[familyId]
+	if family == nil {
+		return
+	}
+	for _, hash := range family.hashes {
+		delete(m.tokens, hash)
+	}
+	delete(m.families, familyId)
+}
+
+// prune removes the expired tokens, used or not, and the families left without tokens
+func (m *InMemoryRefreshTokenStore) prune(now time.Time) {
+	for len(m.expiries) > 0 && !now.Before(m.expiries[0].expiresAt) {
+		expiry := heap.Pop(&m.expiries).(refreshTokenExpiry)
+		token := m.tokens[expiry.hash]
+		if token == nil {
+			// the token was revoked or forgotten before it expired
+			continue
+		}
+		delete(m.tokens, expiry.hash)
+
+		family := m.families[token.FamilyId]
+		for i, hash := range family.hashes {
+			if hash == expiry.hash {
+				family.hashes = append(family.hashes[:i], family.hashes[i+1:]...)
+				break
+			}
+		}
+		if len(family.hashes) == 0 {
+			delete(m.families, token.FamilyId)
+		}
+	}
+}
+
+type refreshTokenExpiry struct {
+	hash      string
+	expiresAt time.Time
+}
+
+// refreshTokenExpiries is a min-heap of the token expiries
+type refreshTokenExpiries []refreshTokenExpiry
+
+func (h refreshTokenExpiries) Len() int           { return len(h) }
+func (h refreshTokenExpiries) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h refreshTokenExpiries) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *refreshTokenExpiries) Push(x interface{}) {
+	*h = append(*h, x.(refreshTokenExpiry))
+}
+
+func (h *refreshTokenExpiries) Pop() interface{} {
+	old := *h
+	expiry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return expiry
+}
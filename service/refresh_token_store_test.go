@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"grpc-go/service"
+	"testing"
+	"time"
+)
+
+func TestInMemoryRefreshTokenStore_BoundedFamily(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryRefreshTokenStore()
+	expiresAt := time.Now().Add(time.Hour)
+	for i := 0; i < 20; i++ {
+		require.NoError(t, store.Save(&service.RefreshToken{
+			Hash:      fmt.Sprintf("hash%d", i),
+			FamilyId:  "family",
+			Username:  "alice",
+			ExpiresAt: expiresAt,
+		}))
+	}
+
+	// the oldest used tokens are forgotten, the recent ones are kept to detect their reuse
+	_, err := store.Use("hash0")
+	require.ErrorIs(t, err, service.ErrNotFound)
+	_, err = store.Use("hash2")
+	require.ErrorIs(t, err, service.ErrNotFound)
+	token, err := store.Use("hash3")
+	require.NoError(t, err)
+	require.Equal(t, "family", token.FamilyId)
+	token, err = store.Use("hash19")
+	require.NoError(t, err)
+	require.False(t, token.Used)
+
+	require.NoError(t, store.RevokeUser("alice"))
+	_, err = store.Use("hash19")
+	require.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestInMemoryRefreshTokenStore_PruneExpired(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryRefreshTokenStore()
+	require.NoError(t, store.Save(&service.RefreshToken{
+		Hash:      "expired",
+		FamilyId:  "family1",
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	_, err := store.Use("expired")
+	require.NoError(t, err)
+
+	// saving another token prunes the expired one even though it was used
+	require.NoError(t, store.Save(&service.RefreshToken{
+		Hash:      "valid",
+		FamilyId:  "family2",
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	_, err = store.Use("expired")
+	require.ErrorIs(t, err, service.ErrNotFound)
+	_, err = store.Use("valid")
+	require.NoError(t, err)
+}
@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"grpc-go/pb"
+	"grpc-go/sample"
+	"grpc-go/service"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestReviewServer starts a review server that authorizes the requests with the access tokens of jwtManager
+func startTestReviewServer(t *testing.T, jwtManager *service.JWTManager, laptopStore service.LaptopStore, ratingStore service.RateStore) pb.ReviewServiceClient {
+	accessibleRoles := map[string][]string{
+		"/grpc.go.ReviewService/CreateReview":      {"admin", "user"},
+		"/grpc.go.ReviewService/VoteReviewHelpful": {"admin", "user"},
+		"/grpc.go.ReviewService/SetReviewStatus":   {"admin"},
+	}
+	authInterceptor := service.NewAuthInterceptor(jwtManager, service.NewInMemoryRevocationList(), accessibleRoles)
+	reviewServer := service.NewReviewServer(service.NewInMemoryReviewStore(), laptopStore, ratingStore, service.DefaultRatingScale)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor.Unary()))
+	pb.RegisterReviewServiceServer(grpcServer, reviewServer)
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	return pb.NewReviewServiceClient(conn)
+}
+
+func TestClientCreateReview(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := service.NewInMemoryRatingStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	reviewClient := startTestReviewServer(t, jwtManager, laptopStore, ratingStore)
+	alice := withTestAccessToken(t, jwtManager, "alice", "user")
+
+	testCase := []struct {
+		name string
+		req  *pb.CreateReviewRequest
+		code codes.Code
+	}{
+		{
+			name: "unknown_laptop",
+			req:  &pb.CreateReviewRequest{LaptopId: "unknown", Score: 7, Body: "fine"},
+			code: codes.NotFound,
+		},
+		{
+			name: "invalid_score",
+			req:  &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 11, Body: "fine"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "empty_body",
+			req:  &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 7, Title: "fine", Body: "  "},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "long_title",
+			req:  &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 7, Title: strings.Repeat("é", 121), Body: "fine"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "long_body",
+			req:  &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 7, Body: strings.Repeat("a", 5001)},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for i := range testCase {
+		tc := testCase[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := reviewClient.CreateReview(alice, tc.req)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+
+	_, err := reviewClient.CreateReview(context.Background(), &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 7, Body: "fine"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	res, err := reviewClient.CreateReview(alice, &pb.CreateReviewRequest{
+		LaptopId: laptop.GetId(),
+		Score:    9,
+		Title:    strings.Repeat("é", 120),
+		Body:     " Great keyboard ",
+	})
+	require.NoError(t, err)
+	review := res.GetReview()
+	require.Equal(t, "alice", review.GetAuthor())
+	require.Equal(t, "Great keyboard", review.GetBody())
+	require.Equal(t, pb.Review_PUBLISHED, review.GetStatus())
+
+	// the score of the review is the rating of its author
+	rating, err := ratingStore.Find(laptop.GetId())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), rating.Count)
+	require.Equal(t, 9.0, rating.Average())
+
+	_, err = reviewClient.CreateReview(alice, &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 3, Body: "changed my mind"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestClientListReviews(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	reviewClient := startTestReviewServer(t, jwtManager, laptopStore, service.NewInMemoryRatingStore())
+
+	var reviews []*pb.Review
+	for i := 0; i < 5; i++ {
+		author := withTestAccessToken(t, jwtManager, fmt.Sprintf("user%d", i), "user")
+		res, err := reviewClient.CreateReview(author, &pb.CreateReviewRequest{LaptopId: laptop.GetId(), Score: 8, Body: "review"})
+		require.NoError(t, err)
+		reviews = append(reviews, res.GetReview())
+	}
+
+	// users can't vote for their own review and vote once
+	voter := func(name string) context.Context {
+		return withTestAccessToken(t, jwtManager, name, "user")
+	}
+	_, err := reviewClient.VoteReviewHelpful(voter("user1"), &pb.VoteReviewHelpfulRequest{ReviewId: reviews[1].GetId(), Helpful: true})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	for _, name := range []string{"bob", "bob", "carol"} {
+		_, err := reviewClient.VoteReviewHelpful(voter(name), &pb.VoteReviewHelpfulRequest{ReviewId: reviews[1].GetId(), Helpful: true})
+		require.NoError(t, err)
+	}
+	vote, err := reviewClient.VoteReviewHelpful(voter("dave"), &pb.VoteReviewHelpfulRequest{ReviewId: reviews[3].GetId(), Helpful: true})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), vote.GetHelpfulCount())
+	vote, err = reviewClient.VoteReviewHelpful(voter("bob"), &pb.VoteReviewHelpfulRequest{ReviewId: reviews[1].GetId()})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), vote.GetHelpfulCount())
+
+	// only admins moderate and hidden reviews aren't listed
+	_, err = reviewClient.SetReviewStatus(voter("bob"), &pb.SetReviewStatusRequest{ReviewId: reviews[4].GetId(), Status: pb.Review_HIDDEN})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	admin := withTestAccessToken(t, jwtManager, "admin", "admin")
+	moderated, err := reviewClient.SetReviewStatus(admin, &pb.SetReviewStatusRequest{ReviewId: reviews[4].GetId(), Status: pb.Review_HIDDEN})
+	require.NoError(t, err)
+	require.Equal(t, pb.Review_HIDDEN, moderated.GetReview().GetStatus())
+	_, err = reviewClient.VoteReviewHelpful(voter("bob"), &pb.VoteReviewHelpfulRequest{ReviewId: reviews[4].GetId(), Helpful: true})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	listAll := func(orderBy pb.ListReviewsRequest_OrderBy) []string {
+		var ids []string
+		token := ""
+		for {
+			res, err := reviewClient.ListReviews(context.Background(), &pb.ListReviewsRequest{
+				LaptopId:  laptop.GetId(),
+				OrderBy:   orderBy,
+				PageSize:  2,
+				PageToken: token,
+			})
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.GetReviews()), 2)
+			for _, review := range res.GetReviews() {
+				ids = append(ids, review.GetId())
+			}
+			token = res.GetNextPageToken()
+			if token == "" {
+				return ids
+			}
+		}
+	}
+
+	newest := listAll(pb.ListReviewsRequest_NEWEST)
+	require.Equal(t, []string{reviews[3].GetId(), reviews[2].GetId(), reviews[1].GetId(), reviews[0].GetId()}, newest)
+	mostHelpful := listAll(pb.ListReviewsRequest_MOST_HELPFUL)
+	require.Len(t, mostHelpful, 4)
+	require.ElementsMatch(t, []string{reviews[1].GetId(), reviews[3].GetId()}, mostHelpful[:2])
+
+	_, err = reviewClient.ListReviews(context.Background(), &pb.ListReviewsRequest{LaptopId: laptop.GetId(), PageSize: 1000})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
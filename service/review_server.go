@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"grpc-go/pb"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxReviewTitleLength = 120
+	maxReviewBodyLength  = 5000
+	// defaultReviewPageSize and maxReviewPageSize bound the number of reviews ListReviews returns
+	defaultReviewPageSize = 20
+	maxReviewPageSize     = 100
+)
+
+type ReviewServer struct {
+	reviewStore ReviewStore
+	laptopStore LaptopStore
+	ratingStore RateStore
+	ratingScale RatingScale
+	pb.UnimplementedReviewServiceServer
+}
+
+// NewReviewServer returns a server that accepts reviews whose score is on the rating scale
+func NewReviewServer(reviewStore ReviewStore, laptopStore LaptopStore, ratingStore RateStore, ratingScale RatingScale) *ReviewServer {
+	return &ReviewServer{
+		reviewStore: reviewStore,
+		laptopStore: laptopStore,
+		ratingStore: ratingStore,
+		ratingScale: ratingScale,
+	}
+}
+
+func (s *ReviewServer) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error) {
+	username, err := authenticatedUsername(ctx)
+	if err != nil {
+		return nil, logError(err)
+	}
+	laptopID := req.GetLaptopId()
+	log.Printf("receive a create-review request from %s for laptop %s", username, laptopID)
+
+	err = validateReview(req, s.ratingScale)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "invalid review: %v", err))
+	}
+
+	laptop, err := s.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find laptop: %v", err))
+	}
+	if laptop == nil {
+		return nil, logError(status.Errorf(codes.NotFound, "laptop id %s doesn't exist", laptopID))
+	}
+
+	review := &pb.Review{
+		Id:        uuid.New().String(),
+		LaptopId:  laptopID,
+		Author:    username,
+		Score:     req.GetScore(),
+		Title:     strings.TrimSpace(req.GetTitle()),
+		Body:      strings.TrimSpace(req.GetBody()),
+		Status:    pb.Review_PUBLISHED,
+		CreatedAt: timestamppb.Now(),
+	}
+	err = s.reviewStore.Save(review)
+	if err != nil {
+		return nil, logError(status.Errorf(storeErrorCode(err), "cannot save review to the store: %v", err))
+	}
+
+	// the score of the review is the rating of its author
+	_, err = s.ratingStore.Add(laptopID, username, review.GetScore())
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot add rating to the store: %v", err))
+	}
+
+	log.Printf("saved review with id: %s", review.GetId())
+	res := &pb.CreateReviewResponse{
+		Review: review,
+	}
+	return res, nil
+}
+
+func validateReview(req *pb.CreateReviewRequest, ratingScale RatingScale) error {
+	err := ratingScale.Validate(req.GetScore())
+	if err != nil {
+		return err
+	}
+	title := strings.TrimSpace(req.GetTitle())
+	if utf8.RuneCountInString(title) > maxReviewTitleLength {
+		return fmt.Errorf("title is longer than %d characters", maxReviewTitleLength)
+	}
+	body := strings.TrimSpace(req.GetBody())
+	if len(body) == 0 {
+		return fmt.Errorf("body is required")
+	}
+	if utf8.RuneCountInString(body) > maxReviewBodyLength {
+		return fmt.Errorf("body is longer than %d characters", maxReviewBodyLength)
+	}
+	return nil
+}
+
+func (s *ReviewServer) ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+	laptopID := req.GetLaptopId()
+	log.Printf("receive a list-reviews request for laptop %s, order by: %v, page size: %d", laptopID, req.GetOrderBy(), req.GetPageSize())
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultReviewPageSize
+	}
+	if pageSize > maxReviewPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page size %d exceeds %d", pageSize, maxReviewPageSize)
+	}
+	var token *reviewPageToken
+	if len(req.GetPageToken()) > 0 {
+		var err error
+		token, err = decodeReviewPageToken(req.GetPageToken())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		if token.OrderBy != req.GetOrderBy() {
+			return nil, status.Errorf(codes.InvalidArgument, "page token was issued for another order")
+		}
+	}
+
+	reviews, err := s.reviewStore.List(laptopID, pb.Review_PUBLISHED)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list reviews: %v", err))
+	}
+
+	// newest or most helpful first, ties are ordered by id so the order is total
+	orderBy := req.GetOrderBy()
+	sort.Slice(reviews, func(i, j int) bool {
+		key1, key2 := reviewSortKey(reviews[i], orderBy), reviewSortKey(reviews[j], orderBy)
+		if key1 != key2 {
+			return key1 > key2
+		}
+		return reviews[i].GetId() < reviews[j].GetId()
+	})
+	start := 0
+	if token != nil {
+		start = sort.Search(len(reviews), func(i int) bool {
+			key := reviewSortKey(reviews[i], orderBy)
+			return key < token.Key || (key == token.Key && reviews[i].GetId() > token.ID)
+		})
+	}
+
+	res := &pb.ListReviewsResponse{}
+	end := start + pageSize
+	if end >= len(reviews) {
+		end = len(reviews)
+	} else {
+		last := reviews[end-1]
+		res.NextPageToken = encodeReviewPageToken(reviewPageToken{
+			OrderBy: orderBy,
+			Key:     reviewSortKey(last, orderBy),
+			ID:      last.GetId(),
+		})
+	}
+	res.Reviews = reviews[start:end]
+	return res, nil
+}
+
+// reviewPageToken is the decoded form of a review page token, it identifies the last review of the previous page
+type reviewPageToken struct {
+	OrderBy pb.ListReviewsRequest_OrderBy `json:"o"`
+	Key     int64                         `json:"k"`
+	ID      string                        `json:"i"`
+}
+
+func reviewSortKey(review *pb.Review, orderBy pb.ListReviewsRequest_OrderBy) int64 {
+	if orderBy == pb.ListReviewsRequest_MOST_HELPFUL {
+		return int64(review.GetHelpfulCount())
+	}
+	return review.GetCreatedAt().AsTime().UnixNano()
+}
+
+func encodeReviewPageToken(token reviewPageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeReviewPageToken(value string) (*reviewPageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token: %w", err)
+	}
+	token := &reviewPageToken{}
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *ReviewServer) VoteReviewHelpful(ctx context.Context, req *pb.VoteReviewHelpfulRequest) (*pb.VoteReviewHelpfulResponse, error) {
+	username, err := authenticatedUsername(ctx)
+	if err != nil {
+		return nil, logError(err)
+	}
+	reviewID := req.GetReviewId()
+	log.Printf("receive a vote-review-helpful request from %s for review %s: %v", username, reviewID, req.GetHelpful())
+
+	review, err := s.reviewStore.Find(reviewID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find review: %v", err))
+	}
+	if review == nil || review.GetStatus() != pb.Review_PUBLISHED {
+		return nil, logError(status.Errorf(codes.NotFound, "review id %s doesn't exist", reviewID))
+	}
+	if review.GetAuthor() == username {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "cannot vote for your own review"))
+	}
+
+	review, err = s.reviewStore.Vote(reviewID, username, req.GetHelpful())
+	if err != nil {
+		return nil, logError(status.Errorf(storeErrorCode(err), "cannot vote for review: %v", err))
+	}
+
+	res := &pb.VoteReviewHelpfulResponse{
+		ReviewId:     reviewID,
+		HelpfulCount: review.GetHelpfulCount(),
+	}
+	return res, nil
+}
+
+func (s *ReviewServer) SetReviewStatus(ctx context.Context, req *pb.SetReviewStatusRequest) (*pb.SetReviewStatusResponse, error) {
+	reviewID := req.GetReviewId()
+	log.Printf("receive a set-review-status request for review %s: %v", reviewID, req.GetStatus())
+
+	_, known := pb.Review_Status_name[int32(req.GetStatus())]
+	if !known {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown review status %d", req.GetStatus())
+	}
+
+	review, err := s.reviewStore.SetStatus(reviewID, req.GetStatus())
+	if errors.Is(err, ErrNotFound) {
+		return nil, logError(status.Errorf(codes.NotFound, "review id %s doesn't exist", reviewID))
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot set review status: %v", err))
+	}
+
+	res := &pb.SetReviewStatusResponse{
+		Review: review,
+	}
+	return res, nil
+}
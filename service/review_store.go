@@ -0,0 +1,116 @@
+package service
+
+import (
+	"google.golang.org/protobuf/proto"
+	"grpc-go/pb"
+	"sync"
+)
+
+type ReviewStore interface {
+	// Save adds the review, it returns ErrAlreadyExist if the author already reviewed the laptop
+	Save(review *pb.Review) error
+	Find(id string) (*pb.Review, error)
+	// List returns the reviews of the laptop that have the given status
+	List(laptopId string, status pb.Review_Status) ([]*pb.Review, error)
+	// SetStatus changes the moderation status of the review, it returns ErrNotFound if the review doesn't exist
+	SetStatus(id string, status pb.Review_Status) (*pb.Review, error)
+	// Vote records whether the user found the review helpful, a user counts once per review
+	Vote(id string, username string, helpful bool) (*pb.Review, error)
+}
+
+// reviewAuthor identifies the review a user wrote about a laptop
+type reviewAuthor struct {
+	laptopId string
+	author   string
+}
+
+type InMemoryReviewStore struct {
+	mutex    sync.RWMutex
+	reviews  map[string]*pb.Review
+	byLaptop map[string][]string
+	authors  map[reviewAuthor]bool
+	// votes holds the users who found each review helpful
+	votes map[string]map[string]bool
+}
+
+func NewInMemoryReviewStore() ReviewStore {
+	return &InMemoryReviewStore{
+		reviews:  make(map[string]*pb.Review),
+		byLaptop: make(map[string][]string),
+		authors:  make(map[reviewAuthor]bool),
+		votes:    make(map[string]map[string]bool),
+	}
+}
+
+func (m *InMemoryReviewStore) Save(review *pb.Review) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	author := reviewAuthor{laptopId: review.GetLaptopId(), author: review.GetAuthor()}
+	if m.reviews[review.GetId()] != nil || m.authors[author] {
+		return ErrAlreadyExist
+	}
+	m.reviews[review.GetId()] = proto.Clone(review).(*pb.Review)
+	m.byLaptop[review.GetLaptopId()] = append(m.byLaptop[review.GetLaptopId()], review.GetId())
+	m.authors[author] = true
+	return nil
+}
+
+func (m *InMemoryReviewStore) Find(id string) (*pb.Review, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	review := m.reviews[id]
+	if review == nil {
+		return nil, nil
+	}
+	return proto.Clone(review).(*pb.Review), nil
+}
+
+func (m *InMemoryReviewStore) List(laptopId string, status pb.Review_Status) ([]*pb.Review, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var reviews []*pb.Review
+	for _, id := range m.byLaptop[laptopId] {
+		review := m.reviews[id]
+		if review.GetStatus() == status {
+			reviews = append(reviews, proto.Clone(review).(*pb.Review))
+		}
+	}
+	return reviews, nil
+}
+
+func (m *InMemoryReviewStore) SetStatus(id string, status pb.Review_Status) (*pb.Review, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	review := m.reviews[id]
+	if review == nil {
+		return nil, ErrNotFound
+	}
+	review.Status = status
+	return proto.Clone(review).(*pb.Review), nil
+}
+
+func (m *InMemoryReviewStore) Vote(id string, username string, helpful bool) (*pb.Review, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	review := m.reviews[id]
+	if review == nil {
+		return nil, ErrNotFound
+	}
+	voters := m.votes[id]
+	if voters == nil {
+		voters = make(map[string]bool)
+		m.votes[id] = voters
+	}
+	if helpful {
+		voters[username] = true
+	} else {
+		delete(voters, username)
+	}
+	review.HelpfulCount = uint32(len(voters))
+	return proto.Clone(review).(*pb.Review), nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"grpc-go/pb"
+	"math"
+	"sort"
+)
+
+// pageToken is the decoded form of a page token. It identifies the last laptop sent to the client,
+// so the next page starts right after it no matter how many laptops were created in between.
+type pageToken struct {
+	OrderBy    pb.SearchLaptopRequest_OrderBy `json:"o"`
+	Descending bool                           `json:"d"`
+	// KeyBits holds the sort key as math.Float64bits to survive the JSON round trip exactly
+	KeyBits uint64 `json:"k"`
+	ID      string `json:"i"`
+}
+
+// sortedLaptop is a search match together with its sort key
+type sortedLaptop struct {
+	laptop *pb.Laptop
+	key    float64
+}
+
+func encodePageToken(token pageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(value string) (*pageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token: %w", err)
+	}
+	token := &pageToken{}
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token: %w", err)
+	}
+	return token, nil
+}
+
+// compareSorted orders by key (reversed if descending) and then by id, so the order is total
+func compareSorted(key1 float64, id1 string, key2 float64, id2 string, descending bool) int {
+	if c := compareFloat(key1, key2); c != 0 {
+		if descending {
+			return -c
+		}
+		return c
+	}
+	switch {
+	case id1 < id2:
+		return -1
+	case id1 > id2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareFloat is a total order on float64 that puts NaN before any other value
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case math.IsNaN(a) && !math.IsNaN(b):
+		return -1
+	case !math.IsNaN(a) && math.IsNaN(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// paginate sorts the matches and returns at most pageSize of them that come after the token.
+// The boolean result reports whether more matches follow the returned page.
+func paginate(matches []sortedLaptop, descending bool, token *pageToken, pageSize uint32) ([]sortedLaptop, bool) {
+	sort.Slice(matches, func(i, j int) bool {
+		return compareSorted(matches[i].key, matches[i].laptop.Id, matches[j].key, matches[j].laptop.Id, descending) < 0
+	})
+
+	start := 0
+	if token != nil {
+		key := math.Float64frombits(token.KeyBits)
+		start = sort.Search(len(matches), func(i int) bool {
+			return compareSorted(matches[i].key, matches[i].laptop.Id, key, token.ID, descending) > 0
+		})
+	}
+	matches = matches[start:]
+
+	if pageSize > 0 && uint32(len(matches)) > pageSize {
+		return matches[:pageSize], true
+	}
+	return matches, false
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"log"
+	"sync"
+	"time"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey is a key the access tokens are signed with, it is identified by the kid header of the tokens
+type SigningKey struct {
+	Id        string
+	Algorithm string
+	CreatedAt time.Time
+	// ExpiresAt is set once the key is rotated out, the tokens it signed are accepted until then
+	ExpiresAt time.Time
+	// signer is the private key, or the secret of an HMAC key
+	signer interface{}
+}
+
+// GenerateSigningKey returns a new key of the asymmetric algorithm: RS256, ES256 or EdDSA
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var signer interface{}
+	var err error
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case jwt.SigningMethodES256.Alg():
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case SigningMethodEdDSA.Alg():
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate %s key: %w", algorithm, err)
+	}
+
+	key := &SigningKey{
+		Id:        uuid.New().String(),
+		Algorithm: algorithm,
+		CreatedAt: time.Now(),
+		signer:    signer,
+	}
+	return key, nil
+}
+
+// PublicKey returns the key the signatures are verified with, it is nil for an HMAC key
+func (key *SigningKey) PublicKey() crypto.PublicKey {
+	signer, ok := key.signer.(crypto.Signer)
+	if !ok {
+		return nil
+	}
+	return signer.Public()
+}
+
+// verificationKey returns the key jwt-go verifies the signatures with
+func (key *SigningKey) verificationKey() interface{} {
+	if publicKey := key.PublicKey(); publicKey != nil {
+		return publicKey
+	}
+	return key.signer
+}
+
+// SigningKeyRing holds the current signing key and the rotated keys that still verify tokens
+type SigningKeyRing struct {
+	mutex       sync.RWMutex
+	gracePeriod time.Duration
+	current     *SigningKey
+	retired     []*SigningKey
+	// path is the file the keys are kept in, empty if they are only kept in memory
+	path      string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewSigningKeyRing returns a ring signing with a new key of the algorithm, the keys are only kept in memory.
+// A rotated key keeps verifying tokens for gracePeriod, which must not be shorter than the token duration.
+func NewSigningKeyRing(algorithm string, gracePeriod time.Duration) (*SigningKeyRing, error) {
+	key, err := GenerateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	ring := &SigningKeyRing{
+		gracePeriod: gracePeriod,
+		current:     key,
+	}
+	return ring, nil
+}
+
+// newHMACKeyRing returns a ring with a single HS256 key without id, it can't be rotated
+func newHMACKeyRing(secretKey string) *SigningKeyRing {
+	return &SigningKeyRing{
+		current: &SigningKey{
+			Algorithm: jwt.SigningMethodHS256.Alg(),
+			CreatedAt: time.Now(),
+			signer:    []byte(secretKey),
+		},
+	}
+}
+
+// Current returns the key new tokens are signed with
+func (ring *SigningKeyRing) Current() *SigningKey {
+	ring.refresh(signingKeyCheckInterval)
+	ring.mutex.RLock()
+	defer ring.mutex.RUnlock()
+	return ring.current
+}
+
+// Find returns the key with the id if it still verifies tokens
+func (ring *SigningKeyRing) Find(id string) (*SigningKey, bool) {
+	key, ok := ring.find(id)
+	if !ok && ring.path != "" {
+		// the key may have been generated by another server sharing the key file
+		ring.refresh(0)
+		key, ok = ring.find(id)
+	}
+	return key, ok
+}
+
+func (ring *SigningKeyRing) find(id string) (*SigningKey, bool) {
+	ring.mutex.RLock()
+	defer ring.mutex.RUnlock()
+
+	if ring.current.Id == id {
+		return ring.current, true
+	}
+	now := time.Now()
+	for _, key := range ring.retired {
+		if key.Id == id && now.Before(key.ExpiresAt) {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the current key followed by the rotated keys that still verify tokens
+func (ring *SigningKeyRing) Keys() []*SigningKey {
+	ring.refresh(signingKeyCheckInterval)
+	ring.mutex.RLock()
+	defer ring.mutex.RUnlock()
+
+	keys := []*SigningKey{ring.current}
+	now := time.Now()
+	for _, key := range ring.retired {
+		if now.Before(key.ExpiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Rotate signs the next tokens with a new key of the same algorithm,
+// the current key verifies tokens until the grace period is over
+func (ring *SigningKeyRing) Rotate() (*SigningKey, error) {
+	algorithm := ring.Current().Algorithm
+	if algorithm == jwt.SigningMethodHS256.Alg() {
+		return nil, fmt.Errorf("cannot rotate %s key", algorithm)
+	}
+	key, err := GenerateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+
+	// the keys rotated by another server sharing the key file are kept
+	unlock, err := ring.lockFile()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if ring.path != "" {
+		err = ring.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	current, retired := ring.current, ring.retired
+	ring.replaceCurrent(key)
+	err = ring.save()
+	if err != nil {
+		ring.current, ring.retired = current, retired
+		return nil, err
+	}
+	return key, nil
+}
+
+// replaceCurrent signs with the key from now on and retires the current key, the ring must be locked
+func (ring *SigningKeyRing) replaceCurrent(key *SigningKey) {
+	now := time.Now()
+	var retired []*SigningKey
+	if ring.current != nil {
+		// keys are immutable once handed out, so the retired key is a copy
+		previous := *ring.current
+		previous.ExpiresAt = now.Add(ring.gracePeriod)
+		retired = append(retired, &previous)
+	}
+	for _, other := range ring.retired {
+		if now.Before(other.ExpiresAt) {
+			retired = append(retired, other)
+		}
+	}
+	ring.current = key
+	ring.retired = retired
+}
+
+// RunKeyRotation rotates the signing key every interval until the context is done
+func RunKeyRotation(ctx context.Context, ring *SigningKeyRing, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// another server sharing the key file may have rotated the key already
+		if time.Since(ring.Current().CreatedAt) < interval/2 {
+			continue
+		}
+		key, err := ring.Rotate()
+		if err != nil {
+			log.Printf("cannot rotate signing key: %v", err)
+			continue
+		}
+		log.Printf("rotated signing key, new key id: %s", key.Id)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	signingKeyFileName = "signing_keys.json"
+	signingKeyLockName = "signing_keys.lock"
+	// signingKeyLockTimeout is how old a lock is when it is taken over, the server holding it must have crashed
+	signingKeyLockTimeout = 10 * time.Second
+	// signingKeyCheckInterval is how often a ring checks if another server rotated the keys in its file
+	signingKeyCheckInterval = time.Second
+)
+
+// signingKeySet is the content of the key file, the current key comes first
+type signingKeySet struct {
+	Keys []*storedSigningKey `json:"keys"`
+}
+
+type storedSigningKey struct {
+	Id        string    `json:"id"`
+	Algorithm string    `json:"algorithm"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// PrivateKey is the PKCS #8 encoding of the private key
+	PrivateKey []byte `json:"private_key"`
+}
+
+// OpenSigningKeyRing returns a ring whose keys are kept in a file of the directory, so they survive a restart
+// and servers sharing the directory verify each other's tokens. A new key is generated if the directory
+// has no keys yet or the current key has another algorithm.
+func OpenSigningKeyRing(dir string, algorithm string, gracePeriod time.Duration) (*SigningKeyRing, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create signing key directory: %w", err)
+	}
+	ring := &SigningKeyRing{
+		gracePeriod: gracePeriod,
+		path:        filepath.Join(dir, signingKeyFileName),
+	}
+
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+
+	// servers starting together must not replace the key the other one generated
+	unlock, err := ring.lockFile()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = ring.load()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if ring.current != nil && ring.current.Algorithm == algorithm {
+		return ring, nil
+	}
+
+	key, err := GenerateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	ring.replaceCurrent(key)
+	err = ring.save()
+	if err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// lockFile keeps the other servers sharing the key file from changing it until the returned function is called,
+// the keys have to be read again once it is locked
+func (ring *SigningKeyRing) lockFile() (func(), error) {
+	if ring.path == "" {
+		return func() {}, nil
+	}
+	lockPath := filepath.Join(filepath.Dir(ring.path), signingKeyLockName)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("cannot lock signing key file: %w", err)
+		}
+		info, err := os.Stat(lockPath)
+		if err == nil && time.Since(info.ModTime()) > signingKeyLockTimeout {
+			log.Printf("remove stale signing key lock %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// refresh loads the key file again if another server changed it since it was read,
+// the file is checked at most once per interval
+func (ring *SigningKeyRing) refresh(interval time.Duration) {
+	if ring.path == "" {
+		return
+	}
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(ring.checkedAt) < interval {
+		return
+	}
+	ring.checkedAt = now
+	info, err := os.Stat(ring.path)
+	if err != nil || info.ModTime().Equal(ring.modTime) {
+		return
+	}
+	err = ring.load()
+	if err != nil {
+		log.Printf("cannot reload signing keys: %v", err)
+	}
+}
+
+// load replaces the keys of the ring with those of the key file
+func (ring *SigningKeyRing) load() error {
+	info, err := os.Stat(ring.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(ring.path)
+	if err != nil {
+		return fmt.Errorf("cannot read signing keys: %w", err)
+	}
+	set := &signingKeySet{}
+	err = json.Unmarshal(data, set)
+	if err != nil {
+		return fmt.Errorf("cannot decode signing keys: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		return fmt.Errorf("signing key file %s has no keys", ring.path)
+	}
+
+	keys := make([]*SigningKey, 0, len(set.Keys))
+	for _, stored := range set.Keys {
+		signer, err := x509.ParsePKCS8PrivateKey(stored.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("cannot decode signing key %s: %w", stored.Id, err)
+		}
+		keys = append(keys, &SigningKey{
+			Id:        stored.Id,
+			Algorithm: stored.Algorithm,
+			CreatedAt: stored.CreatedAt,
+			ExpiresAt: stored.ExpiresAt,
+			signer:    signer,
+		})
+	}
+	ring.current = keys[0]
+	ring.retired = keys[1:]
+	ring.modTime = info.ModTime()
+	return nil
+}
+
+// save replaces the key file with the keys of the ring, it does nothing for a ring kept in memory
+func (ring *SigningKeyRing) save() error {
+	if ring.path == "" {
+		return nil
+	}
+	set := signingKeySet{}
+	for _, key := range append([]*SigningKey{ring.current}, ring.retired...) {
+		privateKey, err := x509.MarshalPKCS8PrivateKey(key.signer)
+		if err != nil {
+			return fmt.Errorf("cannot encode signing key %s: %w", key.Id, err)
+		}
+		set.Keys = append(set.Keys, &storedSigningKey{
+			Id:         key.Id,
+			Algorithm:  key.Algorithm,
+			CreatedAt:  key.CreatedAt,
+			ExpiresAt:  key.ExpiresAt,
+			PrivateKey: privateKey,
+		})
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("cannot encode signing keys: %w", err)
+	}
+
+	tmpPath := ring.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot create signing key file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write signing key file: %w", err)
+	}
+
+	err = os.Rename(tmpPath, ring.path)
+	if err != nil {
+		return fmt.Errorf("cannot rename signing key file: %w", err)
+	}
+	err = syncDir(filepath.Dir(ring.path))
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(ring.path)
+	if err == nil {
+		ring.modTime = info.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// revocationPruneInterval is how often the entries of expired tokens are removed from the revocation list
+const revocationPruneInterval = time.Minute
+
+// RevocationList holds the access tokens that must be rejected before they expire
+type RevocationList interface {
+	// Revoke rejects the token with the id, the entry is kept until the token expires
+	Revoke(tokenId string, expiresAt time.Time) error
+	// RevokeUser rejects the tokens of the user issued until issuedBefore, the entry is kept until expiresAt
+	RevokeUser(username string, issuedBefore time.Time, expiresAt time.Time) error
+	IsRevoked(claims *UserClaims) (bool, error)
+}
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+type InMemoryRevocationList struct {
+	mutex     sync.RWMutex
+	tokens    map[string]time.Time
+	users     map[string]userRevocation
+	lastPrune time.Time
+}
+
+func NewInMemoryRevocationList() RevocationList {
+	return &InMemoryRevocationList{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]userRevocation),
+	}
+}
+
+func (m *InMemoryRevocationList) Revoke(tokenId string, expiresAt time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.prune(time.Now())
+	m.tokens[tokenId] = expiresAt
+	return nil
+}
+
+func (m *InMemoryRevocationList) RevokeUser(username string, issuedBefore time.Time, expiresAt time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.prune(time.Now())
+	m.users[username] = userRevocation{issuedBefore: issuedBefore, expiresAt: expiresAt}
+	return nil
+}
+
+func (m *InMemoryRevocationList) IsRevoked(claims *UserClaims) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	_, revoked := m.tokens[claims.Id]
+	if revoked {
+		return true, nil
+	}
+	user, found := m.users[claims.Username]
+	// iat has a precision of a second, so the tokens issued in the second of the revocation are rejected too
+	if found && !time.Unix(claims.IssuedAt, 0).After(user.issuedBefore) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// prune removes the entries whose tokens expired anyway
+func (m *InMemoryRevocationList) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < revocationPruneInterval {
+		return
+	}
+	m.lastPrune = now
+
+	for tokenId, expiresAt := range m.tokens {
+		if now.After(expiresAt) {
+			delete(m.tokens, tokenId)
+		}
+	}
+	for username, user := range m.users {
+		if now.After(user.expiresAt) {
+			delete(m.users, username)
+		}
+	}
+}
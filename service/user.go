@@ -3,26 +3,76 @@ package service
 import (
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 32
+	minPasswordLength = 8
+	// maxPasswordLength is the number of bytes bcrypt hashes, the rest would be ignored
+	maxPasswordLength = 72
+)
+
+// UserStatus tells if the user can log in
+type UserStatus int
+
+const (
+	UserActive UserStatus = iota
+	// UserDisabled users cannot log in and their tokens are rejected
+	UserDisabled
 )
 
 type User struct {
 	Username       string
 	HashedPassword string
 	Role           string
+	Status         UserStatus
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func NewUser(username, password, role string) (*User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	now := time.Now()
+	user := &User{
+		Username:  username,
+		Role:      role,
+		Status:    UserActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := user.SetPassword(password)
 	if err != nil {
-		return nil, fmt.Errorf("cannot hash password, err: %v", err)
+		return nil, err
 	}
+	return user, nil
+}
 
-	user := &User{
-		Username:       username,
-		HashedPassword: string(hashedPassword),
-		Role:           role,
+// SetPassword replaces the hashed password of the user
+func (user *User) SetPassword(password string) error {
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.HashedPassword = hashedPassword
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// hashPassword returns the bcrypt hash of the password, it is slow so it shouldn't be called under a lock
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash password, err: %v", err)
 	}
-	return user, err
+	return string(hashedPassword), nil
+}
+
+func (user *User) IsDisabled() bool {
+	return user.Status == UserDisabled
 }
 
 func (user *User) IsCorrectPassword(password string) bool {
@@ -35,5 +85,39 @@ func (user *User) Clone() *User {
 		Username:       user.Username,
 		HashedPassword: user.HashedPassword,
 		Role:           user.Role,
+		Status:         user.Status,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+// ValidateUsername accepts 3 to 32 letters, digits, dots, dashes and underscores
+func ValidateUsername(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < minUsernameLength || length > maxUsernameLength {
+		return fmt.Errorf("username must have %d to %d characters", minUsernameLength, maxUsernameLength)
+	}
+	for _, r := range username {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._-", r) {
+			return fmt.Errorf("username cannot contain %q", r)
+		}
+	}
+	return nil
+}
+
+// ValidatePassword accepts passwords of 8 to 72 bytes with a letter and a digit that don't contain the username
+func ValidatePassword(password string, username string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must have at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must have at most %d bytes", maxPasswordLength)
+	}
+	if strings.IndexFunc(password, unicode.IsLetter) < 0 || strings.IndexFunc(password, unicode.IsDigit) < 0 {
+		return fmt.Errorf("password must contain a letter and a digit")
+	}
+	if len(username) > 0 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("password cannot contain the username")
 	}
+	return nil
 }
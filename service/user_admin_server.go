@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"grpc-go/pb"
+	"log"
+	"time"
+)
+
+const (
+	// defaultUserPageSize and maxUserPageSize bound the number of users ListUsers returns
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+type UserAdminServer struct {
+	userStore         UserStore
+	jwtManager        *JWTManager
+	refreshTokenStore RefreshTokenStore
+	revocationList    RevocationList
+	pb.UnimplementedUserAdminServiceServer
+}
+
+// NewUserAdminServer returns a server that revokes the tokens of the users it disables or deletes
+func NewUserAdminServer(userStore UserStore, jwtManager *JWTManager, refreshTokenStore RefreshTokenStore, revocationList RevocationList) *UserAdminServer {
+	return &UserAdminServer{
+		userStore:         userStore,
+		jwtManager:        jwtManager,
+		refreshTokenStore: refreshTokenStore,
+		revocationList:    revocationList,
+	}
+}
+
+func (s *UserAdminServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	log.Printf("receive a list-users request, page size: %d", req.GetPageSize())
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultUserPageSize
+	}
+	if pageSize > maxUserPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page size %d exceeds %d", pageSize, maxUserPageSize)
+	}
+	after := ""
+	if len(req.GetPageToken()) > 0 {
+		var err error
+		after, err = decodeUserPageToken(req.GetPageToken())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+	}
+
+	// one more user tells if there is a next page
+	users, err := s.userStore.List(after, pageSize+1)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list users: %v", err))
+	}
+
+	res := &pb.ListUsersResponse{}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		res.NextPageToken = encodeUserPageToken(users[pageSize-1].Username)
+	}
+	for _, user := range users {
+		res.Users = append(res.Users, toPbUser(user))
+	}
+	return res, nil
+}
+
+// encodeUserPageToken returns the token of the page following the user
+func encodeUserPageToken(username string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(username))
+}
+
+func decodeUserPageToken(value string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("malformed page token: %w", err)
+	}
+	return string(data), nil
+}
+
+func (s *UserAdminServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	user, err := s.findUser(req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.GetUserResponse{
+		User: toPbUser(user),
+	}
+	return res, nil
+}
+
+func (s *UserAdminServer) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.SetUserRoleResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a set-user-role request for %s: %s", username, req.GetRole())
+
+	if req.GetRole() != "admin" && req.GetRole() != "user" {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown role %q", req.GetRole())
+	}
+	err := s.checkNotSelf(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	user, err := s.updateUser(username, func(user *User) error {
+		if user.Role != req.GetRole() {
+			user.Role = req.GetRole()
+			user.UpdatedAt = time.Now()
+			changed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		// the role is a claim of the access tokens, refreshing them picks up the new role
+		err = revokeAccessTokens(username, s.jwtManager, s.revocationList)
+		if err != nil {
+			return nil, logError(err)
+		}
+	}
+
+	res := &pb.SetUserRoleResponse{
+		User: toPbUser(user),
+	}
+	return res, nil
+}
+
+func (s *UserAdminServer) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a disable-user request for %s", username)
+
+	err := s.checkNotSelf(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.setStatus(username, UserDisabled)
+	if err != nil {
+		return nil, err
+	}
+	// the tokens issued before are rejected from now on
+	err = revokeUserTokens(username, s.jwtManager, s.revocationList, s.refreshTokenStore)
+	if err != nil {
+		return nil, logError(err)
+	}
+
+	res := &pb.DisableUserResponse{
+		User: toPbUser(user),
+	}
+	return res, nil
+}
+
+func (s *UserAdminServer) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive an enable-user request for %s", username)
+
+	user, err := s.setStatus(username, UserActive)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.EnableUserResponse{
+		User: toPbUser(user),
+	}
+	return res, nil
+}
+
+func (s *UserAdminServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	username := req.GetUsername()
+	log.Printf("receive a delete-user request for %s", username)
+
+	err := s.checkNotSelf(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	err = s.userStore.Delete(username)
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "user %s doesn't exist", username)
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot delete user: %v", err))
+	}
+	// a user registering the same username later must not inherit the tokens
+	err = revokeUserTokens(username, s.jwtManager, s.revocationList, s.refreshTokenStore)
+	if err != nil {
+		return nil, logError(err)
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
+
+// checkNotSelf keeps an admin from locking themselves out
+func (s *UserAdminServer) checkNotSelf(ctx context.Context, username string) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if ok && claims.Username == username {
+		return status.Errorf(codes.FailedPrecondition, "cannot change your own account")
+	}
+	return nil
+}
+
+func (s *UserAdminServer) findUser(username string) (*User, error) {
+	user, err := s.userStore.Find(username)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot find user: %v", err))
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %s doesn't exist", username)
+	}
+	return user, nil
+}
+
+func (s *UserAdminServer) setStatus(username string, userStatus UserStatus) (*User, error) {
+	return s.updateUser(username, func(user *User) error {
+		if user.Status != userStatus {
+			user.Status = userStatus
+			user.UpdatedAt = time.Now()
+		}
+		return nil
+	})
+}
+
+// updateUser applies the change to the stored user, so it doesn't undo a concurrent change of another field
+func (s *UserAdminServer) updateUser(username string, change func(user *User) error) (*User, error) {
+	user, err := s.userStore.Update(username, change)
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "user %s doesn't exist", username)
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot update user: %v", err))
+	}
+	return user, nil
+}
+
+func toPbUser(user *User) *pb.User {
+	userStatus := pb.User_ACTIVE
+	if user.IsDisabled() {
+		userStatus = pb.User_DISABLED
+	}
+	return &pb.User{
+		Username:  user.Username,
+		Role:      user.Role,
+		Status:    userStatus,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
@@ -0,0 +1,224 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"grpc-go/pb"
+	"grpc-go/service"
+	"testing"
+	"time"
+)
+
+type testUserAdmin struct {
+	authServer      *service.AuthServer
+	adminServer     *service.UserAdminServer
+	authInterceptor *service.AuthInterceptor
+	userStore       *updateHookStore
+}
+
+// updateHookStore runs beforeUpdate once before the next update, to change the user while a slower change is made
+type updateHookStore struct {
+	service.UserStore
+	beforeUpdate func()
+}
+
+func (store *updateHookStore) Update(username string, change func(user *service.User) error) (*service.User, error) {
+	if hook := store.beforeUpdate; hook != nil {
+		store.beforeUpdate = nil
+		hook()
+	}
+	return store.UserStore.Update(username, change)
+}
+
+// newTestUserAdmin returns the servers sharing the stores of the users root (admin) and alice (user)
+func newTestUserAdmin(t *testing.T) *testUserAdmin {
+	userStore := &updateHookStore{UserStore: service.NewInMemoryUserStore()}
+	for username, role := range map[string]string{"root": "admin", "alice": "user"} {
+		user, err := service.NewUser(username, "secret", role)
+		require.NoError(t, err)
+		require.NoError(t, userStore.Save(user))
+	}
+
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	refreshTokenStore := service.NewInMemoryRefreshTokenStore()
+	revocationList := service.NewInMemoryRevocationList()
+	return &testUserAdmin{
+		authServer:  service.NewAuthServer(userStore, jwtManager, refreshTokenStore, time.Hour, revocationList),
+		adminServer: service.NewUserAdminServer(userStore, jwtManager, refreshTokenStore, revocationList),
+		authInterceptor: service.NewAuthInterceptor(jwtManager, revocationList, map[string][]string{
+			protectedMethod: {"admin", "user"},
+		}),
+		userStore: userStore,
+	}
+}
+
+// adminContext returns the context of a request authorized with the access token of root
+func (admin *testUserAdmin) adminContext(t *testing.T) context.Context {
+	login, err := admin.authServer.Login(context.Background(), &pb.LoginRequest{Username: "root", Password: "secret"})
+	require.NoError(t, err)
+
+	var ctx context.Context
+	err = callWithToken(admin.authInterceptor, protectedMethod, login.GetAccessToken(), func(authorized context.Context, req interface{}) (interface{}, error) {
+		ctx = authorized
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestUserAdminServer_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	admin := newTestUserAdmin(t)
+	ctx := context.Background()
+	expected := []string{"alice", "root"}
+	for i := 0; i < 5; i++ {
+		username := fmt.Sprintf("user%d", i)
+		user, err := service.NewUser(username, "secret", "user")
+		require.NoError(t, err)
+		require.NoError(t, admin.userStore.Save(user))
+		expected = append(expected, username)
+	}
+
+	var usernames []string
+	pageToken := ""
+	for {
+		res, err := admin.adminServer.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 3, PageToken: pageToken})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(res.GetUsers()), 3)
+		for _, user := range res.GetUsers() {
+			require.Equal(t, pb.User_ACTIVE, user.GetStatus())
+			require.NotNil(t, user.GetCreatedAt())
+			usernames = append(usernames, user.GetUsername())
+		}
+		pageToken = res.GetNextPageToken()
+		if pageToken == "" {
+			break
+		}
+	}
+	require.Equal(t, expected, usernames)
+
+	_, err := admin.adminServer.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 101})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = admin.adminServer.ListUsers(ctx, &pb.ListUsersRequest{PageToken: "%"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUserAdminServer_SetUserRole(t *testing.T) {
+	t.Parallel()
+
+	admin := newTestUserAdmin(t)
+	ctx := admin.adminContext(t)
+
+	login, err := admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	res, err := admin.adminServer.SetUserRole(ctx, &pb.SetUserRoleRequest{Username: "alice", Role: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, "admin", res.GetUser().GetRole())
+
+	// the token with the old role is rejected, a refreshed one carries the new role
+	err = callWithToken(admin.authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = admin.authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.NoError(t, err)
+
+	get, err := admin.adminServer.GetUser(ctx, &pb.GetUserRequest{Username: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, "admin", get.GetUser().GetRole())
+
+	_, err = admin.adminServer.SetUserRole(ctx, &pb.SetUserRoleRequest{Username: "alice", Role: "owner"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = admin.adminServer.SetUserRole(ctx, &pb.SetUserRoleRequest{Username: "unknown", Role: "user"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = admin.adminServer.SetUserRole(ctx, &pb.SetUserRoleRequest{Username: "root", Role: "user"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestUserAdminServer_DisableUser(t *testing.T) {
+	t.Parallel()
+
+	admin := newTestUserAdmin(t)
+	ctx := admin.adminContext(t)
+
+	login, err := admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	res, err := admin.adminServer.DisableUser(ctx, &pb.DisableUserRequest{Username: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, pb.User_DISABLED, res.GetUser().GetStatus())
+
+	// the disabled user cannot log in, and the tokens issued before are rejected
+	_, err = admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = callWithToken(admin.authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = admin.authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	enabled, err := admin.adminServer.EnableUser(ctx, &pb.EnableUserRequest{Username: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, pb.User_ACTIVE, enabled.GetUser().GetStatus())
+	_, err = admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	_, err = admin.adminServer.DisableUser(ctx, &pb.DisableUserRequest{Username: "root"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = admin.adminServer.EnableUser(ctx, &pb.EnableUserRequest{Username: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestUserAdminServer_DisableUserDuringPasswordChange(t *testing.T) {
+	t.Parallel()
+
+	admin := newTestUserAdmin(t)
+	ctx := admin.adminContext(t)
+
+	login, err := admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	// alice is disabled while the new password is hashed
+	admin.userStore.beforeUpdate = func() {
+		_, err := admin.adminServer.DisableUser(ctx, &pb.DisableUserRequest{Username: "alice"})
+		require.NoError(t, err)
+	}
+	err = callWithToken(admin.authInterceptor, protectedMethod, login.GetAccessToken(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		return admin.authServer.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "secret", NewPassword: "n3w-password"})
+	})
+	require.NoError(t, err)
+
+	// the password change doesn't enable alice again
+	user, err := admin.userStore.Find("alice")
+	require.NoError(t, err)
+	require.True(t, user.IsDisabled())
+	require.True(t, user.IsCorrectPassword("n3w-password"))
+	_, err = admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "n3w-password"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestUserAdminServer_DeleteUser(t *testing.T) {
+	t.Parallel()
+
+	admin := newTestUserAdmin(t)
+	ctx := admin.adminContext(t)
+
+	login, err := admin.authServer.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	_, err = admin.adminServer.DeleteUser(ctx, &pb.DeleteUserRequest{Username: "alice"})
+	require.NoError(t, err)
+	_, err = admin.adminServer.GetUser(ctx, &pb.GetUserRequest{Username: "alice"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	err = callWithToken(admin.authInterceptor, protectedMethod, login.GetAccessToken(), okHandler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = admin.authServer.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: login.GetRefreshToken()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = admin.adminServer.DeleteUser(ctx, &pb.DeleteUserRequest{Username: "alice"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = admin.adminServer.DeleteUser(ctx, &pb.DeleteUserRequest{Username: "root"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -1,10 +1,20 @@
 package service
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type UserStore interface {
 	Save(user *User) error
 	Find(username string) (*User, error)
+	// Update applies the change to the stored user while no other change can interleave and returns
+	// the updated user. Nothing is stored if the change fails, it returns ErrNotFound if the user doesn't exist.
+	Update(username string, change func(user *User) error) (*User, error)
+	// List returns at most limit users whose username sorts after the given one, ordered by username
+	List(after string, limit int) ([]*User, error)
+	// Delete removes the user, it returns ErrNotFound if the user doesn't exist
+	Delete(username string) error
 }
 
 type InMemoryUserStore struct {
@@ -37,3 +47,50 @@ func (m *InMemoryUserStore) Find(username string) (*User, error) {
 	}
 	return user.Clone(), nil
 }
+
+func (m *InMemoryUserStore) Update(username string, change func(user *User) error) (*User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.users[username] == nil {
+		return nil, ErrNotFound
+	}
+	user := m.users[username].Clone()
+	err := change(user)
+	if err != nil {
+		return nil, err
+	}
+	m.users[username] = user
+	return user.Clone(), nil
+}
+
+func (m *InMemoryUserStore) List(after string, limit int) ([]*User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	usernames := make([]string, 0, len(m.users))
+	for username := range m.users {
+		if username > after {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	if len(usernames) > limit {
+		usernames = usernames[:limit]
+	}
+
+	users := make([]*User, 0, len(usernames))
+	for _, username := range usernames {
+		users = append(users, m.users[username].Clone())
+	}
+	return users, nil
+}
+
+func (m *InMemoryUserStore) Delete(username string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.users[username] == nil {
+		return ErrNotFound
+	}
+	delete(m.users, username)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package units converts the measures of a laptop to the units they are compared in
+package units
+
+import "grpc-go/pb"
+
+// PoundToKg converts weight_lb to kilograms
+const PoundToKg = 0.45359237
+
+// ToBit returns the size of the memory in bits
+func ToBit(memory *pb.Memory) uint64 {
+	value := memory.GetValue()
+
+	switch memory.GetUnit() {
+	case pb.Memory_BIT:
+		return value
+	case pb.Memory_BYTE:
+		return value << 3 // 8 = 2^3
+	case pb.Memory_KILOBYTE:
+		return value << 13 // 1024 * 8 = 2^10 * 2^3 = 2^13
+	case pb.Memory_MEGABYTE:
+		return value << 23
+	case pb.Memory_GIGABYTE:
+		return value << 33
+	case pb.Memory_TERABYTE:
+		return value << 43
+	default:
+		return 0
+	}
+}
+
+// TotalStorage returns the capacity in bits of all laptop storages with the given driver
+func TotalStorage(laptop *pb.Laptop, driver pb.Storage_Driver) uint64 {
+	var total uint64
+	for _, storage := range laptop.GetStorages() {
+		if storage.GetDriver() == driver {
+			total += ToBit(storage.GetMemory())
+		}
+	}
+	return total
+}
+
+// WeightKg returns the laptop weight in kilograms whichever unit it was given in, 0 if it is unknown
+func WeightKg(laptop *pb.Laptop) float64 {
+	switch weight := laptop.GetWeight().(type) {
+	case *pb.Laptop_WeightKg:
+		return weight.WeightKg
+	case *pb.Laptop_WeightLb:
+		return weight.WeightLb * PoundToKg
+	default:
+		return 0
+	}
+}
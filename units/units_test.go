@@ -0,0 +1,38 @@
+package units_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"grpc-go/pb"
+	"grpc-go/units"
+	"testing"
+)
+
+func TestToBit(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, uint64(8), units.ToBit(&pb.Memory{Value: 1, Unit: pb.Memory_BYTE}))
+	require.Equal(t, uint64(16)<<33, units.ToBit(&pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}))
+	require.Equal(t, uint64(0), units.ToBit(nil))
+}
+
+func TestTotalStorage(t *testing.T) {
+	t.Parallel()
+
+	laptop := &pb.Laptop{
+		Storages: []*pb.Storage{
+			{Driver: pb.Storage_SSD, Memory: &pb.Memory{Value: 256, Unit: pb.Memory_GIGABYTE}},
+			{Driver: pb.Storage_SSD, Memory: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}},
+			{Driver: pb.Storage_HDD, Memory: &pb.Memory{Value: 2, Unit: pb.Memory_TERABYTE}},
+		},
+	}
+	require.Equal(t, uint64(256)<<33+uint64(1)<<43, units.TotalStorage(laptop, pb.Storage_SSD))
+	require.Equal(t, uint64(2)<<43, units.TotalStorage(laptop, pb.Storage_HDD))
+}
+
+func TestWeightKg(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 1.5, units.WeightKg(&pb.Laptop{Weight: &pb.Laptop_WeightKg{WeightKg: 1.5}}))
+	require.InDelta(t, 0.45359237*2, units.WeightKg(&pb.Laptop{Weight: &pb.Laptop_WeightLb{WeightLb: 2}}), 1e-9)
+	require.Equal(t, 0.0, units.WeightKg(&pb.Laptop{}))
+}